@@ -40,6 +40,10 @@ func Example() {
 
 func Underscore(_ int, _ float32) {}
 
+func Variadic(prefix string, items ...int) {
+	fmt.Println(prefix, len(items))
+}
+
 func main() {
 	context := &traceContext{
 		traceID: "123",
@@ -58,6 +62,9 @@ func main() {
 	m.NewField = "abc"
 	m.Example()
 
+	// Call a variadic function, the hook receives the spread items
+	Variadic("items", 1, 2, 3)
+
 	// Call real module function
 	println(rate.Every(time.Duration(1)))
 }
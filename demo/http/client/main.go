@@ -13,7 +13,13 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -22,13 +28,16 @@ const (
 )
 
 var (
-	addr     = flag.String("addr", "http://localhost:8080", "the server address to connect to")
-	name     = flag.String("name", "world", "Name to greet")
-	method   = flag.String("method", "GET", "HTTP method to use (GET or POST)")
-	count    = flag.Int("count", 1, "Number of requests to send")
-	logLevel = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
-	shutdown = flag.Bool("shutdown", false, "Shutdown the server")
-	logger   *slog.Logger
+	addr       = flag.String("addr", "http://localhost:8080", "the server address to connect to")
+	name       = flag.String("name", "world", "Name to greet")
+	method     = flag.String("method", "GET", "HTTP method to use (GET or POST)")
+	count      = flag.Int("count", 1, "Number of requests to send")
+	logLevel   = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	shutdown   = flag.Bool("shutdown", false, "Shutdown the server")
+	custom     = flag.Bool("custom-transport", false, "Send requests through a custom transport")
+	countSpans = flag.Bool("count-spans", false, "Record spans and log the number of client spans on exit")
+	logSpans   = flag.Bool("log-spans", false, "Log every span and propagate the trace context to the server")
+	logger     *slog.Logger
 )
 
 type GreetRequest struct {
@@ -43,6 +52,61 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// loggingTransport is a custom transport logging every round trip of the
+// wrapped transport
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger.Debug("round trip", "method", req.Method, "url", req.URL.String())
+	return t.base.RoundTrip(req)
+}
+
+// clientSpanCounter counts the client spans ended by the instrumentation
+type clientSpanCounter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (*clientSpanCounter) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (c *clientSpanCounter) OnEnd(span sdktrace.ReadOnlySpan) {
+	if span.SpanKind() != trace.SpanKindClient {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count++
+}
+
+func (*clientSpanCounter) Shutdown(context.Context) error   { return nil }
+func (*clientSpanCounter) ForceFlush(context.Context) error { return nil }
+
+func (c *clientSpanCounter) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// spanLogger logs the identifiers of every span ended by the instrumentation,
+// so that the spans of the client and the server can be correlated
+type spanLogger struct{}
+
+func (spanLogger) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (spanLogger) OnEnd(span sdktrace.ReadOnlySpan) {
+	logger.Info("span ended",
+		"span_name", span.Name(),
+		"span_kind", span.SpanKind().String(),
+		"trace_id", span.SpanContext().TraceID().String(),
+		"span_id", span.SpanContext().SpanID().String(),
+		"parent_span_id", span.Parent().SpanID().String())
+}
+
+func (spanLogger) Shutdown(context.Context) error   { return nil }
+func (spanLogger) ForceFlush(context.Context) error { return nil }
+
 func makeRequest(ctx context.Context, client *http.Client, requestMethod, targetURL, name string) error {
 	var req *http.Request
 	var err error
@@ -139,9 +203,26 @@ func main() {
 	logger = slog.New(slog.NewJSONHandler(os.Stdout, opts))
 	slog.SetDefault(logger)
 
+	var spans *clientSpanCounter
+	var tpOpts []sdktrace.TracerProviderOption
+	if *countSpans {
+		spans = &clientSpanCounter{}
+		tpOpts = append(tpOpts, sdktrace.WithSpanProcessor(spans))
+	}
+	if *logSpans {
+		tpOpts = append(tpOpts, sdktrace.WithSpanProcessor(spanLogger{}))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	}
+	if len(tpOpts) > 0 {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(tpOpts...))
+	}
+
 	client := &http.Client{
 		Timeout: defaultTimeout,
 	}
+	if *custom {
+		client.Transport = &loggingTransport{base: http.DefaultTransport}
+	}
 
 	ctx := context.Background()
 	url := *addr + "/greet"
@@ -189,4 +270,7 @@ func main() {
 		"total_requests", *count,
 		"successful", successCount,
 		"failed", failureCount)
+	if spans != nil {
+		logger.Info("spans recorded", "client_spans", spans.Count())
+	}
 }
@@ -27,6 +27,7 @@ var (
 	disableFaults  = flag.Bool("no-faults", false, "Disable fault injection")
 	disableLatency = flag.Bool("no-latency", false, "Disable artificial latency")
 	logLevel       = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	logSpans       = flag.Bool("log-spans", false, "Log every span and extract the trace context of the client")
 	logger         *slog.Logger
 )
 
@@ -189,6 +190,10 @@ func main() {
 	logger = slog.New(slog.NewJSONHandler(os.Stdout, opts))
 	slog.SetDefault(logger)
 
+	if *logSpans {
+		setupSpanLogging()
+	}
+
 	http.HandleFunc("/greet", greetHandler)
 	http.HandleFunc("/health", healthHandler)
 	http.HandleFunc("/shutdown", shutdownHandler)
@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !otelnoop
+
+package main
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// spanLogger logs the identifiers of every span ended by the instrumentation,
+// so that the spans of the client and the server can be correlated
+type spanLogger struct{}
+
+func (spanLogger) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (spanLogger) OnEnd(span sdktrace.ReadOnlySpan) {
+	logger.Info("span ended",
+		"span_name", span.Name(),
+		"span_kind", span.SpanKind().String(),
+		"trace_id", span.SpanContext().TraceID().String(),
+		"span_id", span.SpanContext().SpanID().String(),
+		"parent_span_id", span.Parent().SpanID().String())
+}
+
+func (spanLogger) Shutdown(context.Context) error   { return nil }
+func (spanLogger) ForceFlush(context.Context) error { return nil }
+
+// setupSpanLogging logs the spans of the instrumentation and extracts the W3C
+// trace context of the clients
+func setupSpanLogging() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanLogger{})))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
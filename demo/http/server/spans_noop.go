@@ -0,0 +1,10 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build otelnoop
+
+package main
+
+// setupSpanLogging does nothing as the otelnoop tag compiles the
+// instrumentation out, the OpenTelemetry SDK is not linked either
+func setupSpanLogging() {}
@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package code
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+type CodeAttrsGetter[REQUEST any] interface {
+	// GetCodeFunction returns the name of the instrumented function
+	GetCodeFunction(request REQUEST) string
+	// GetCodeNamespace returns the package of the instrumented function
+	GetCodeNamespace(request REQUEST) string
+}
+
+// CodeAttrsExtractor identifies the function wrapped by a span with the
+// code.function.name and code.namespace attributes. Hooks know the function
+// from the HookContext, which the tool populates when generating the
+// trampolines.
+type CodeAttrsExtractor[REQUEST any, RESPONSE any, GETTER CodeAttrsGetter[REQUEST]] struct {
+	Getter GETTER
+}
+
+func (c *CodeAttrsExtractor[REQUEST, RESPONSE, GETTER]) OnStart(parentContext context.Context,
+	attributes []attribute.KeyValue, request REQUEST,
+) ([]attribute.KeyValue, context.Context) {
+	if function := c.Getter.GetCodeFunction(request); function != "" {
+		attributes = append(attributes, semconv.CodeFunctionName(function))
+	}
+	if namespace := c.Getter.GetCodeNamespace(request); namespace != "" {
+		attributes = append(attributes, semconv.CodeNamespace(namespace))
+	}
+	return attributes, parentContext
+}
+
+func (c *CodeAttrsExtractor[REQUEST, RESPONSE, GETTER]) OnEnd(context context.Context,
+	attributes []attribute.KeyValue, _ REQUEST, _ RESPONSE, _ error,
+) ([]attribute.KeyValue, context.Context) {
+	return attributes, context
+}
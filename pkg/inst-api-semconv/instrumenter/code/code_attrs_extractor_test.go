@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package code
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+type testFunction struct {
+	namespace string
+	name      string
+}
+
+type testCodeGetter struct{}
+
+func (testCodeGetter) GetCodeFunction(request testFunction) string {
+	return request.name
+}
+
+func (testCodeGetter) GetCodeNamespace(request testFunction) string {
+	return request.namespace
+}
+
+type testSpanNameExtractor struct{}
+
+func (testSpanNameExtractor) Extract(request testFunction) string {
+	return request.name
+}
+
+func TestCodeAttrsExtractor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	originalTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	defer otel.SetTracerProvider(originalTP)
+
+	builder := &instrumenter.Builder[testFunction, any]{}
+	inst := builder.Init().
+		SetSpanNameExtractor(testSpanNameExtractor{}).
+		SetSpanKindExtractor(&instrumenter.AlwaysInternalExtractor[testFunction]{}).
+		AddAttributesExtractor(&CodeAttrsExtractor[testFunction, any, testCodeGetter]{}).
+		BuildInstrumenter()
+	request := testFunction{namespace: "main", name: "Example"}
+	ctx := inst.Start(context.Background(), request)
+	inst.End(ctx, instrumenter.Invocation[testFunction, any]{Request: request})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, trace.SpanKindInternal, spans[0].SpanKind())
+	assert.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		semconv.CodeFunctionName("Example"),
+		semconv.CodeNamespace("main"),
+	})
+}
+
+func TestCodeAttrsExtractorEmpty(t *testing.T) {
+	extractor := &CodeAttrsExtractor[testFunction, any, testCodeGetter]{}
+	attrs, _ := extractor.OnStart(context.Background(), nil, testFunction{})
+	assert.Empty(t, attrs)
+}
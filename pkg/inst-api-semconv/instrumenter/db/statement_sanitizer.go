@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Dialect describes how a SQL dialect quotes identifiers and literals.
+type Dialect struct {
+	// IdentifierQuotes enclose quoted identifiers, which are kept as is.
+	IdentifierQuotes string
+	// StringQuotes enclose string literals, which are replaced with "?".
+	StringQuotes string
+	// BackslashEscapes reports whether a backslash escapes the next character
+	// inside a quoted string or identifier.
+	BackslashEscapes bool
+}
+
+var (
+	// StandardDialect follows ANSI SQL: 'string' and "identifier".
+	StandardDialect = Dialect{IdentifierQuotes: `"`, StringQuotes: `'`}
+	// MySQLDialect treats both quote styles as strings and `identifier`
+	// as identifiers, with backslash escapes.
+	MySQLDialect = Dialect{IdentifierQuotes: "`", StringQuotes: `'"`, BackslashEscapes: true}
+	// PostgreSQLDialect is the standard dialect, $1 style placeholders are
+	// kept since they are not literals.
+	PostgreSQLDialect = StandardDialect
+	// SQLServerDialect additionally accepts [identifier].
+	SQLServerDialect = Dialect{IdentifierQuotes: `"[`, StringQuotes: `'`}
+)
+
+// StatementSanitizer normalizes a SQL statement for use as the db.statement
+// attribute. It is stateless and safe for concurrent use.
+type StatementSanitizer struct {
+	Dialect Dialect
+	// MaxLength truncates the sanitized statement to at most MaxLength bytes,
+	// zero or negative means no limit.
+	MaxLength int
+}
+
+// NewStatementSanitizer returns a sanitizer for the standard dialect with no
+// length limit.
+func NewStatementSanitizer() StatementSanitizer {
+	return StatementSanitizer{Dialect: StandardDialect}
+}
+
+// Sanitize replaces string and numeric literals with "?", collapses runs of
+// whitespace outside of quoted identifiers into a single space and truncates
+// the result to MaxLength.
+func (s StatementSanitizer) Sanitize(statement string) string {
+	var b strings.Builder
+	b.Grow(len(statement))
+	pendingSpace := false
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+		if isSpace(c) {
+			pendingSpace = b.Len() > 0
+			continue
+		}
+		if pendingSpace {
+			b.WriteByte(' ')
+			pendingSpace = false
+		}
+		switch {
+		case strings.IndexByte(s.Dialect.StringQuotes, c) >= 0:
+			i = s.skipQuoted(statement, i, c)
+			b.WriteByte('?')
+		case strings.IndexByte(s.Dialect.IdentifierQuotes, c) >= 0:
+			end := s.skipQuoted(statement, i, closingQuote(c))
+			b.WriteString(statement[i:min(end+1, len(statement))])
+			i = end
+		case isDigit(c) && (i == 0 || !isIdentChar(statement[i-1])):
+			i = skipNumber(statement, i)
+			b.WriteByte('?')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return truncate(b.String(), s.MaxLength)
+}
+
+// skipQuoted returns the index of the quote closing the literal that starts
+// at start, or the last index if the literal is unterminated.
+func (s StatementSanitizer) skipQuoted(statement string, start int, quote byte) int {
+	for i := start + 1; i < len(statement); i++ {
+		c := statement[i]
+		if c == '\\' && s.Dialect.BackslashEscapes {
+			i++
+			continue
+		}
+		if c == quote {
+			// A doubled quote is an escaped quote
+			if i+1 < len(statement) && statement[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(statement) - 1
+}
+
+// skipNumber returns the index of the last character of the numeric literal
+// that starts at start, covering decimals, exponents and hex literals.
+func skipNumber(statement string, start int) int {
+	i := start
+	if statement[i] == '0' && i+1 < len(statement) && (statement[i+1] == 'x' || statement[i+1] == 'X') {
+		i++
+		for i+1 < len(statement) && isHexDigit(statement[i+1]) {
+			i++
+		}
+		return i
+	}
+	for i+1 < len(statement) {
+		next := statement[i+1]
+		switch {
+		case isDigit(next), next == '.':
+			i++
+		case (next == 'e' || next == 'E') && i+2 < len(statement) &&
+			(isDigit(statement[i+2]) || statement[i+2] == '+' || statement[i+2] == '-'):
+			i += 2
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func closingQuote(c byte) byte {
+	if c == '[' {
+		return ']'
+	}
+	return c
+}
+
+// truncate cuts s to at most maxLength bytes without splitting a rune.
+func truncate(s string, maxLength int) string {
+	if maxLength <= 0 || len(s) <= maxLength {
+		return s
+	}
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isIdentChar(c byte) bool {
+	return isDigit(c) || c == '_' || c == '$' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeStandardDialect(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		expected  string
+	}{
+		{
+			name:      "literals",
+			statement: "SELECT * FROM users WHERE id = 42 AND name = 'bob' AND score > 3.5e-2",
+			expected:  "SELECT * FROM users WHERE id = ? AND name = ? AND score > ?",
+		},
+		{
+			name:      "escaped quote",
+			statement: "SELECT * FROM users WHERE name = 'o''brien' AND id = 1",
+			expected:  "SELECT * FROM users WHERE name = ? AND id = ?",
+		},
+		{
+			name:      "quoted identifiers are kept",
+			statement: `SELECT "user id", "a""b" FROM "Table 1" WHERE "x" = 'y'`,
+			expected:  `SELECT "user id", "a""b" FROM "Table 1" WHERE "x" = ?`,
+		},
+		{
+			name:      "whitespace is normalized",
+			statement: "  SELECT a,\n\tb   FROM t\r\nWHERE c = 0x1F  ",
+			expected:  "SELECT a, b FROM t WHERE c = ?",
+		},
+		{
+			name:      "whitespace inside literals is dropped with the literal",
+			statement: "INSERT INTO t VALUES ('a   b', 1)",
+			expected:  "INSERT INTO t VALUES (?, ?)",
+		},
+		{
+			name:      "placeholders and identifiers with digits are kept",
+			statement: "SELECT col1 FROM table2 WHERE x = $1 AND y = ?",
+			expected:  "SELECT col1 FROM table2 WHERE x = $1 AND y = ?",
+		},
+		{
+			name:      "unterminated literal",
+			statement: "SELECT 'abc",
+			expected:  "SELECT ?",
+		},
+	}
+	s := NewStatementSanitizer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, s.Sanitize(tt.statement))
+		})
+	}
+}
+
+func TestSanitizeMySQLDialect(t *testing.T) {
+	s := StatementSanitizer{Dialect: MySQLDialect}
+	assert.Equal(t,
+		"SELECT `user id` FROM `t` WHERE a = ? AND b = ?",
+		s.Sanitize("SELECT `user id` FROM `t` WHERE a = \"x\" AND b = 'it\\'s'"))
+}
+
+func TestSanitizeSQLServerDialect(t *testing.T) {
+	s := StatementSanitizer{Dialect: SQLServerDialect}
+	assert.Equal(t,
+		"SELECT [user id] FROM [dbo].[t] WHERE a = ?",
+		s.Sanitize("SELECT [user id] FROM [dbo].[t] WHERE a = 'x'"))
+}
+
+func TestSanitizeMaxLength(t *testing.T) {
+	s := StatementSanitizer{Dialect: StandardDialect, MaxLength: 10}
+	assert.Equal(t, "SELECT * F", s.Sanitize("SELECT * FROM users"))
+	// Never split a multi-byte rune
+	s.MaxLength = 8
+	assert.Equal(t, "SELECT ", s.Sanitize("SELECT é"))
+	s.MaxLength = 0
+	assert.Equal(t, "SELECT * FROM users", s.Sanitize("SELECT * FROM users"))
+}
+
+func BenchmarkSanitizeShort(b *testing.B) {
+	s := NewStatementSanitizer()
+	statement := "SELECT * FROM users WHERE id = 42 AND name = 'bob'"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.Sanitize(statement)
+	}
+}
+
+func BenchmarkSanitizeLong(b *testing.B) {
+	s := StatementSanitizer{Dialect: StandardDialect, MaxLength: 2048}
+	statement := "INSERT INTO events (id, name, payload) VALUES " +
+		strings.Repeat("(12345, 'event name', 'some   payload with ''quotes'''),\n", 100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.Sanitize(statement)
+	}
+}
@@ -5,6 +5,10 @@ package http
 
 import (
 	"context"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
 	"sync/atomic"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -29,28 +33,129 @@ type HTTPCommonAttrsExtractor[REQUEST HTTPRequest, RESPONSE HTTPResponse,
 	COMMONATTRGETTER HTTPCommonAttrsGetter[REQUEST, RESPONSE]] struct {
 	HTTPGetter       COMMONATTRGETTER
 	AttributesFilter func(attrs []attribute.KeyValue) []attribute.KeyValue
+	// CapturedRequestHeaders lists the request headers recorded as
+	// http.request.header.<name> attributes
+	CapturedRequestHeaders []string
+	// CaptureHeadersOnError defers the capture of request headers to OnEnd and
+	// only records them when the request failed, keeping the happy path cheap
+	CaptureHeadersOnError bool
+	// KnownMethods replaces the methods reported verbatim as
+	// http.request.method, DefaultKnownMethods is used when empty
+	KnownMethods []string
+}
+
+const (
+	httpRequestHeaderPrefix  = "http.request.header."
+	httpResponseHeaderPrefix = "http.response.header."
+)
+
+// HTTPResponseBodyUncompressedSizeKey is the size of the response body after
+// its Content-Encoding was decoded, http.response.body.size being the size on
+// the wire
+const HTTPResponseBodyUncompressedSizeKey = attribute.Key("http.response.body.uncompressed_size")
+
+// HTTPResponseContentTypeKey is the media type of the response without its
+// parameters, e.g. application/json for application/json; charset=utf-8
+const HTTPResponseContentTypeKey = attribute.Key("http.response.content_type")
+
+// knownMethods are the methods reported verbatim as http.request.method, any
+// other method is reported as _OTHER with the original in
+// http.request.method_original. Matching is case-sensitive as per semconv.
+var knownMethods = map[string]struct{}{
+	http.MethodConnect: {},
+	http.MethodDelete:  {},
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+	http.MethodPatch:   {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodTrace:   {},
+}
+
+// DefaultKnownMethods returns the standard semconv HTTP methods, e.g. to be
+// extended with custom methods before setting them as KnownMethods.
+func DefaultKnownMethods() []string {
+	methods := make([]string, 0, len(knownMethods))
+	for method := range knownMethods {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+func (h *HTTPCommonAttrsExtractor[REQUEST, RESPONSE, COMMONATTRGETTER]) isKnownMethod(method string) bool {
+	if len(h.KnownMethods) > 0 {
+		return slices.Contains(h.KnownMethods, method)
+	}
+	_, ok := knownMethods[method]
+	return ok
 }
 
 func (h *HTTPCommonAttrsExtractor[REQUEST, RESPONSE, COMMONATTRGETTER]) OnStart(parentContext context.Context,
 	attributes []attribute.KeyValue,
 	request REQUEST,
 ) ([]attribute.KeyValue, context.Context) {
-	attributes = append(attributes, attribute.KeyValue{
-		Key:   semconv.HTTPRequestMethodKey,
-		Value: attribute.StringValue(h.HTTPGetter.GetRequestMethod(request)),
-	})
+	method := h.HTTPGetter.GetRequestMethod(request)
+	if h.isKnownMethod(method) {
+		attributes = append(attributes, attribute.KeyValue{
+			Key:   semconv.HTTPRequestMethodKey,
+			Value: attribute.StringValue(method),
+		})
+	} else {
+		attributes = append(attributes, semconv.HTTPRequestMethodOther, semconv.HTTPRequestMethodOriginal(method))
+	}
+	if !h.CaptureHeadersOnError {
+		attributes = h.appendRequestHeaders(attributes, request)
+	}
 	return attributes, parentContext
 }
 
+// appendRequestHeaders appends the captured request headers that are present
+// in the request, header names are normalized to lowercase
+func (h *HTTPCommonAttrsExtractor[REQUEST, RESPONSE, COMMONATTRGETTER]) appendRequestHeaders(
+	attributes []attribute.KeyValue,
+	request REQUEST,
+) []attribute.KeyValue {
+	for _, name := range h.CapturedRequestHeaders {
+		values := h.HTTPGetter.GetHTTPRequestHeader(request, name)
+		if len(values) == 0 {
+			continue
+		}
+		attributes = append(attributes, attribute.StringSlice(httpRequestHeaderPrefix+strings.ToLower(name), values))
+	}
+	return attributes
+}
+
+// appendRequestHeadersOnError appends the deferred request headers when err is
+// set or the status code is below 100 or at least errorStatusCode
+func (h *HTTPCommonAttrsExtractor[REQUEST, RESPONSE, COMMONATTRGETTER]) appendRequestHeadersOnError(
+	attributes []attribute.KeyValue,
+	request REQUEST, response RESPONSE, err error,
+	errorStatusCode int,
+) []attribute.KeyValue {
+	if !h.CaptureHeadersOnError {
+		return attributes
+	}
+	statusCode := h.HTTPGetter.GetHTTPResponseStatusCode(request, response, err)
+	if err == nil && statusCode >= 100 && statusCode < errorStatusCode {
+		return attributes
+	}
+	return h.appendRequestHeaders(attributes, request)
+}
+
 func (h *HTTPCommonAttrsExtractor[REQUEST, RESPONSE, COMMONATTRGETTER]) OnEnd(context context.Context,
 	attributes []attribute.KeyValue,
 	request REQUEST, response RESPONSE, err error,
 ) ([]attribute.KeyValue, context.Context) {
-	statusCode := h.HTTPGetter.GetHTTPResponseStatusCode(request, response, err)
-	attributes = append(attributes, attribute.KeyValue{
-		Key:   semconv.HTTPResponseStatusCodeKey,
-		Value: attribute.IntValue(statusCode),
-	})
+	// There is no status code when no response was received, e.g. when the
+	// connection was refused
+	if statusCode := h.HTTPGetter.GetHTTPResponseStatusCode(request, response, err); statusCode > 0 {
+		attributes = append(attributes, attribute.KeyValue{
+			Key:   semconv.HTTPResponseStatusCodeKey,
+			Value: attribute.IntValue(statusCode),
+		})
+	}
 	errorType := h.HTTPGetter.GetErrorType(request, response, err)
 	if errorType != "" {
 		attributes = append(
@@ -61,8 +166,33 @@ func (h *HTTPCommonAttrsExtractor[REQUEST, RESPONSE, COMMONATTRGETTER]) OnEnd(co
 	return attributes, context
 }
 
+// appendBodySizes appends the body sizes known to getters implementing
+// HTTPBodySizeGetter and HTTPUncompressedBodySizeGetter
+func (h *HTTPCommonAttrsExtractor[REQUEST, RESPONSE, COMMONATTRGETTER]) appendBodySizes(
+	attributes []attribute.KeyValue,
+	request REQUEST, response RESPONSE,
+) []attribute.KeyValue {
+	if getter, ok := any(h.HTTPGetter).(HTTPBodySizeGetter[REQUEST, RESPONSE]); ok {
+		if size := getter.GetHTTPRequestBodySize(request); size >= 0 {
+			attributes = append(attributes, semconv.HTTPRequestBodySizeKey.Int64(size))
+		}
+		if size := getter.GetHTTPResponseBodySize(request, response); size >= 0 {
+			attributes = append(attributes, semconv.HTTPResponseBodySizeKey.Int64(size))
+		}
+	}
+	if getter, ok := any(h.HTTPGetter).(HTTPUncompressedBodySizeGetter[REQUEST, RESPONSE]); ok {
+		if size := getter.GetHTTPResponseUncompressedBodySize(request, response); size >= 0 {
+			attributes = append(attributes, HTTPResponseBodyUncompressedSizeKey.Int64(size))
+		}
+	}
+	return attributes
+}
+
 type HTTPClientAttrsExtractor[REQUEST HTTPRequest, RESPONSE HTTPResponse, GETTER1 HTTPClientAttrsGetter[REQUEST, RESPONSE]] struct {
 	Base HTTPCommonAttrsExtractor[REQUEST, RESPONSE, GETTER1]
+	// CapturedResponseHeaders lists the response headers recorded as
+	// http.response.header.<name> attributes
+	CapturedResponseHeaders []string
 }
 
 func (h *HTTPClientAttrsExtractor[REQUEST, RESPONSE, CLIENTATTRGETTER]) OnStart(parentContext context.Context,
@@ -97,12 +227,66 @@ func (h *HTTPClientAttrsExtractor[REQUEST, RESPONSE, CLIENTATTRGETTER]) OnEnd(
 	request REQUEST, response RESPONSE, err error,
 ) ([]attribute.KeyValue, context.Context) {
 	attributes, context = h.Base.OnEnd(context, attributes, request, response, err)
+	attributes = h.Base.appendRequestHeadersOnError(attributes, request, response, err, 400)
+	attributes = h.Base.appendBodySizes(attributes, request, response)
+	attributes = h.appendResponseHeaders(attributes, request, response, err)
+	attributes = h.appendContentType(attributes, request, response, err)
 	if h.Base.AttributesFilter != nil {
 		attributes = h.Base.AttributesFilter(attributes)
 	}
 	return attributes, context
 }
 
+// appendResponseHeaders appends the captured response headers that are present
+// in the response, nothing is recorded when the request failed without one
+func (h *HTTPClientAttrsExtractor[REQUEST, RESPONSE, CLIENTATTRGETTER]) appendResponseHeaders(
+	attributes []attribute.KeyValue,
+	request REQUEST, response RESPONSE, err error,
+) []attribute.KeyValue {
+	if err != nil {
+		return attributes
+	}
+	for _, name := range h.CapturedResponseHeaders {
+		values := h.Base.HTTPGetter.GetHTTPResponseHeader(request, response, name)
+		if len(values) == 0 {
+			continue
+		}
+		attributes = append(attributes, attribute.StringSlice(httpResponseHeaderPrefix+strings.ToLower(name), values))
+	}
+	return attributes
+}
+
+// appendContentType appends the normalized media type of the response. Unlike
+// the full header it is recorded regardless of CapturedResponseHeaders, its
+// parameters being stripped keeps it low-cardinality.
+func (h *HTTPClientAttrsExtractor[REQUEST, RESPONSE, CLIENTATTRGETTER]) appendContentType(
+	attributes []attribute.KeyValue,
+	request REQUEST, response RESPONSE, err error,
+) []attribute.KeyValue {
+	if err != nil {
+		return attributes
+	}
+	values := h.Base.HTTPGetter.GetHTTPResponseHeader(request, response, "Content-Type")
+	if len(values) == 0 {
+		return attributes
+	}
+	if mediaType := normalizeContentType(values[0]); mediaType != "" {
+		attributes = append(attributes, HTTPResponseContentTypeKey.String(mediaType))
+	}
+	return attributes
+}
+
+// normalizeContentType strips the parameters of a Content-Type header value
+// and lowercases its media type
+func normalizeContentType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	// Malformed parameters are still stripped
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
 func (_ *HTTPClientAttrsExtractor[REQUEST, RESPONSE, CLIENTATTRGETTER]) GetSpanKey() attribute.Key {
 	return utils.HTTPClientKey
 }
@@ -140,11 +324,13 @@ func (h *HTTPServerAttrsExtractor[REQUEST, RESPONSE, SERVERATTRGETTER]) OnEnd(
 	request REQUEST, response RESPONSE, err error,
 ) ([]attribute.KeyValue, context.Context) {
 	attributes, context = h.Base.OnEnd(context, attributes, request, response, err)
+	attributes = h.Base.appendRequestHeadersOnError(attributes, request, response, err, 500)
 	route := h.Base.HTTPGetter.GetHTTPRoute(request)
 	attributes = append(attributes, attribute.KeyValue{
 		Key:   semconv.HTTPRouteKey,
 		Value: attribute.StringValue(route),
 	})
+	attributes = h.Base.appendBodySizes(attributes, request, response)
 	if h.Base.AttributesFilter != nil {
 		attributes = h.Base.AttributesFilter(attributes)
 	}
@@ -5,6 +5,7 @@ package http
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -207,3 +208,310 @@ func TestResendCountHandling(t *testing.T) {
 		t.Fatalf("wrong attributes length")
 	}
 }
+
+type headerClientGetter struct {
+	httpClientAttrsGetter
+	statusCode int
+}
+
+func (headerClientGetter) GetHTTPRequestHeader(_ testRequest, name string) []string {
+	if name == "X-Debug" {
+		return []string{"debug-value"}
+	}
+	return nil
+}
+
+func (h headerClientGetter) GetHTTPResponseStatusCode(_ testRequest, _ testResponse, _ error) int {
+	return h.statusCode
+}
+
+func (headerClientGetter) GetHTTPResponseHeader(_ testRequest, _ testResponse, name string) []string {
+	if name == "Content-Type" {
+		return []string{"application/json"}
+	}
+	return nil
+}
+
+func findAttr(attrs []attribute.KeyValue, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestHTTPClientExtractorCapturesRequestHeaders(t *testing.T) {
+	httpClientExtractor := HTTPClientAttrsExtractor[testRequest, testResponse, headerClientGetter]{
+		Base: HTTPCommonAttrsExtractor[testRequest, testResponse, headerClientGetter]{
+			HTTPGetter:             headerClientGetter{statusCode: 200},
+			CapturedRequestHeaders: []string{"X-Debug", "X-Missing"},
+		},
+	}
+	attrs, _ := httpClientExtractor.OnStart(context.Background(), nil, testRequest{})
+	value, ok := findAttr(attrs, "http.request.header.x-debug")
+	if !ok || value.AsStringSlice()[0] != "debug-value" {
+		t.Fatalf("request header should be captured on start, got %v", attrs)
+	}
+	if _, ok = findAttr(attrs, "http.request.header.x-missing"); ok {
+		t.Fatal("absent header should not be captured")
+	}
+}
+
+func TestHTTPClientExtractorCapturesRequestHeadersOnError(t *testing.T) {
+	const headerKey = attribute.Key("http.request.header.x-debug")
+	tests := []struct {
+		name       string
+		statusCode int
+		err        error
+		captured   bool
+	}{
+		{name: "success", statusCode: 200},
+		{name: "error status code", statusCode: 503, captured: true},
+		{name: "error", statusCode: 200, err: errors.New("connection reset"), captured: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClientExtractor := HTTPClientAttrsExtractor[testRequest, testResponse, headerClientGetter]{
+				Base: HTTPCommonAttrsExtractor[testRequest, testResponse, headerClientGetter]{
+					HTTPGetter:             headerClientGetter{statusCode: tt.statusCode},
+					CapturedRequestHeaders: []string{"X-Debug"},
+					CaptureHeadersOnError:  true,
+				},
+			}
+			attrs, ctx := httpClientExtractor.OnStart(context.Background(), nil, testRequest{})
+			if _, ok := findAttr(attrs, headerKey); ok {
+				t.Fatal("request header capture should be deferred to end")
+			}
+			attrs, _ = httpClientExtractor.OnEnd(ctx, nil, testRequest{}, testResponse{}, tt.err)
+			if _, ok := findAttr(attrs, headerKey); ok != tt.captured {
+				t.Fatalf("request header captured = %v, want %v", ok, tt.captured)
+			}
+		})
+	}
+}
+
+func TestHTTPClientExtractorCapturesResponseHeaders(t *testing.T) {
+	const headerKey = attribute.Key("http.response.header.content-type")
+	httpClientExtractor := HTTPClientAttrsExtractor[testRequest, testResponse, headerClientGetter]{
+		Base: HTTPCommonAttrsExtractor[testRequest, testResponse, headerClientGetter]{
+			HTTPGetter: headerClientGetter{statusCode: 200},
+		},
+		CapturedResponseHeaders: []string{"Content-Type", "X-Missing"},
+	}
+	attrs, ctx := httpClientExtractor.OnStart(context.Background(), nil, testRequest{})
+	attrs, _ = httpClientExtractor.OnEnd(ctx, attrs, testRequest{}, testResponse{}, nil)
+	value, ok := findAttr(attrs, headerKey)
+	if !ok || value.AsStringSlice()[0] != "application/json" {
+		t.Fatalf("response header should be captured on end, got %v", attrs)
+	}
+	if _, ok = findAttr(attrs, "http.response.header.x-missing"); ok {
+		t.Fatal("absent header should not be captured")
+	}
+
+	attrs, _ = httpClientExtractor.OnEnd(ctx, nil, testRequest{}, testResponse{}, errors.New("connection refused"))
+	if _, ok = findAttr(attrs, headerKey); ok {
+		t.Fatal("no response header should be captured without a response")
+	}
+}
+
+type contentTypeClientGetter struct {
+	httpClientAttrsGetter
+	contentType string
+}
+
+func (c contentTypeClientGetter) GetHTTPResponseHeader(_ testRequest, _ testResponse, name string) []string {
+	if name == "Content-Type" && c.contentType != "" {
+		return []string{c.contentType}
+	}
+	return nil
+}
+
+func TestHTTPClientExtractorContentType(t *testing.T) {
+	const headerKey = attribute.Key("http.response.header.content-type")
+	tests := []struct {
+		name            string
+		contentType     string
+		capturedHeaders []string
+		want            string
+	}{
+		{
+			name:            "json with charset",
+			contentType:     "application/json; charset=utf-8",
+			capturedHeaders: []string{"Content-Type"},
+			want:            "application/json",
+		},
+		{name: "header not captured", contentType: "text/html; charset=ISO-8859-1", want: "text/html"},
+		{name: "uppercase", contentType: "Application/JSON", want: "application/json"},
+		{name: "malformed parameters", contentType: "text/plain; charset", want: "text/plain"},
+		{name: "absent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClientExtractor := HTTPClientAttrsExtractor[testRequest, testResponse, contentTypeClientGetter]{
+				Base: HTTPCommonAttrsExtractor[testRequest, testResponse, contentTypeClientGetter]{
+					HTTPGetter: contentTypeClientGetter{contentType: tt.contentType},
+				},
+				CapturedResponseHeaders: tt.capturedHeaders,
+			}
+			attrs, _ := httpClientExtractor.OnEnd(context.Background(), nil, testRequest{}, testResponse{}, nil)
+			value, ok := findAttr(attrs, HTTPResponseContentTypeKey)
+			if ok != (tt.want != "") || value.AsString() != tt.want {
+				t.Fatalf("content type should be %q, got %v", tt.want, attrs)
+			}
+			header, ok := findAttr(attrs, headerKey)
+			if ok != (len(tt.capturedHeaders) > 0) {
+				t.Fatalf("content type header captured = %v, want %v", ok, len(tt.capturedHeaders) > 0)
+			}
+			if ok && header.AsStringSlice()[0] != tt.contentType {
+				t.Fatalf("content type header should be recorded verbatim, got %v", header)
+			}
+		})
+	}
+
+	httpClientExtractor := HTTPClientAttrsExtractor[testRequest, testResponse, contentTypeClientGetter]{
+		Base: HTTPCommonAttrsExtractor[testRequest, testResponse, contentTypeClientGetter]{
+			HTTPGetter: contentTypeClientGetter{contentType: "application/json"},
+		},
+	}
+	attrs, _ := httpClientExtractor.OnEnd(context.Background(), nil, testRequest{}, testResponse{}, errors.New("connection refused"))
+	if _, ok := findAttr(attrs, HTTPResponseContentTypeKey); ok {
+		t.Fatal("no content type should be recorded without a response")
+	}
+}
+
+type bodySizeServerGetter struct {
+	httpServerAttrsGetter
+	requestSize  int64
+	responseSize int64
+}
+
+func (b bodySizeServerGetter) GetHTTPRequestBodySize(_ testRequest) int64 {
+	return b.requestSize
+}
+
+func (b bodySizeServerGetter) GetHTTPResponseBodySize(_ testRequest, _ testResponse) int64 {
+	return b.responseSize
+}
+
+func TestHTTPServerExtractorBodySize(t *testing.T) {
+	httpServerExtractor := HTTPServerAttrsExtractor[testRequest, testResponse, bodySizeServerGetter]{
+		Base: HTTPCommonAttrsExtractor[testRequest, testResponse, bodySizeServerGetter]{
+			HTTPGetter: bodySizeServerGetter{requestSize: -1, responseSize: 42},
+		},
+	}
+	attrs, _ := httpServerExtractor.OnEnd(context.Background(), nil, testRequest{}, testResponse{}, nil)
+	if _, ok := findAttr(attrs, semconv.HTTPRequestBodySizeKey); ok {
+		t.Fatal("unknown request body size should not be recorded")
+	}
+	value, ok := findAttr(attrs, semconv.HTTPResponseBodySizeKey)
+	if !ok || value.AsInt64() != 42 {
+		t.Fatalf("response body size should be recorded, got %v", attrs)
+	}
+}
+
+type bodySizeClientGetter struct {
+	headerClientGetter
+	responseSize             int64
+	uncompressedResponseSize int64
+}
+
+func (bodySizeClientGetter) GetHTTPRequestBodySize(_ testRequest) int64 {
+	return -1
+}
+
+func (b bodySizeClientGetter) GetHTTPResponseBodySize(_ testRequest, _ testResponse) int64 {
+	return b.responseSize
+}
+
+func (b bodySizeClientGetter) GetHTTPResponseUncompressedBodySize(_ testRequest, _ testResponse) int64 {
+	return b.uncompressedResponseSize
+}
+
+func TestHTTPClientExtractorBodySize(t *testing.T) {
+	tests := []struct {
+		name             string
+		getter           bodySizeClientGetter
+		wantSize         int64
+		wantUncompressed int64
+	}{
+		{name: "identity", getter: bodySizeClientGetter{responseSize: 42, uncompressedResponseSize: -1}, wantSize: 42, wantUncompressed: -1},
+		{name: "decoded", getter: bodySizeClientGetter{responseSize: 20, uncompressedResponseSize: 42}, wantSize: 20, wantUncompressed: 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClientExtractor := HTTPClientAttrsExtractor[testRequest, testResponse, bodySizeClientGetter]{
+				Base: HTTPCommonAttrsExtractor[testRequest, testResponse, bodySizeClientGetter]{
+					HTTPGetter: tt.getter,
+				},
+			}
+			attrs, _ := httpClientExtractor.OnEnd(context.Background(), nil, testRequest{}, testResponse{}, nil)
+			if _, ok := findAttr(attrs, semconv.HTTPRequestBodySizeKey); ok {
+				t.Fatal("unknown request body size should not be recorded")
+			}
+			value, ok := findAttr(attrs, semconv.HTTPResponseBodySizeKey)
+			if !ok || value.AsInt64() != tt.wantSize {
+				t.Fatalf("response body size should be %d, got %v", tt.wantSize, attrs)
+			}
+			value, ok = findAttr(attrs, HTTPResponseBodyUncompressedSizeKey)
+			if tt.wantUncompressed < 0 {
+				if ok {
+					t.Fatal("uncompressed size should only be recorded for decoded bodies")
+				}
+			} else if !ok || value.AsInt64() != tt.wantUncompressed {
+				t.Fatalf("uncompressed size should be %d, got %v", tt.wantUncompressed, attrs)
+			}
+		})
+	}
+}
+
+type methodAttrsGetter struct {
+	httpServerAttrsGetter
+}
+
+func (methodAttrsGetter) GetRequestMethod(request testRequest) string {
+	return request.Method
+}
+
+func TestHTTPExtractorNormalizesMethod(t *testing.T) {
+	httpServerExtractor := HTTPServerAttrsExtractor[testRequest, testResponse, methodAttrsGetter]{
+		Base: HTTPCommonAttrsExtractor[testRequest, testResponse, methodAttrsGetter]{},
+	}
+	parentContext := context.Background()
+	attrs, _ := httpServerExtractor.OnStart(parentContext, nil, testRequest{Method: "PATCH"})
+	if attrs[0].Key != semconv.HTTPRequestMethodKey || attrs[0].Value.AsString() != "PATCH" {
+		t.Fatalf("known method should be kept, got %v", attrs[0])
+	}
+	for _, attr := range attrs {
+		if attr.Key == semconv.HTTPRequestMethodOriginalKey {
+			t.Fatal("original method should only be recorded for unknown methods")
+		}
+	}
+
+	attrs, _ = httpServerExtractor.OnStart(parentContext, nil, testRequest{Method: "FROBNICATE"})
+	if attrs[0].Key != semconv.HTTPRequestMethodKey || attrs[0].Value.AsString() != "_OTHER" {
+		t.Fatalf("unknown method should be _OTHER, got %v", attrs[0])
+	}
+	if attrs[1].Key != semconv.HTTPRequestMethodOriginalKey || attrs[1].Value.AsString() != "FROBNICATE" {
+		t.Fatalf("original method should be FROBNICATE, got %v", attrs[1])
+	}
+}
+
+func TestHTTPExtractorCustomKnownMethods(t *testing.T) {
+	httpServerExtractor := HTTPServerAttrsExtractor[testRequest, testResponse, methodAttrsGetter]{
+		Base: HTTPCommonAttrsExtractor[testRequest, testResponse, methodAttrsGetter]{
+			KnownMethods: append(DefaultKnownMethods(), "PROPFIND", "MKCOL"),
+		},
+	}
+	parentContext := context.Background()
+	for _, method := range []string{"PROPFIND", "MKCOL", "GET"} {
+		attrs, _ := httpServerExtractor.OnStart(parentContext, nil, testRequest{Method: method})
+		if attrs[0].Key != semconv.HTTPRequestMethodKey || attrs[0].Value.AsString() != method {
+			t.Fatalf("custom known method %s should be kept, got %v", method, attrs[0])
+		}
+	}
+	attrs, _ := httpServerExtractor.OnStart(parentContext, nil, testRequest{Method: "FROBNICATE"})
+	if attrs[0].Value.AsString() != "_OTHER" {
+		t.Fatalf("unknown method should still be _OTHER, got %v", attrs[0])
+	}
+}
@@ -19,3 +19,27 @@ type HTTPServerAttrsGetter[REQUEST any, RESPONSE any] interface {
 type HTTPClientAttrsGetter[REQUEST any, RESPONSE any] interface {
 	HTTPCommonAttrsGetter[REQUEST, RESPONSE]
 }
+
+// HTTPServerAddressGetter is optionally implemented by client getters to name
+// spans after the server they call, see
+// HTTPClientSpanNameExtractor.WithServerAddress.
+type HTTPServerAddressGetter[REQUEST any] interface {
+	GetServerAddress(request REQUEST) string
+}
+
+// HTTPBodySizeGetter is optionally implemented by getters to record the
+// http.request.body.size and http.response.body.size attributes, and metrics
+// on servers. Sizes are the ones on the wire, i.e. of compressed bodies, and
+// -1 when unknown.
+type HTTPBodySizeGetter[REQUEST any, RESPONSE any] interface {
+	GetHTTPRequestBodySize(request REQUEST) int64
+	GetHTTPResponseBodySize(request REQUEST, response RESPONSE) int64
+}
+
+// HTTPUncompressedBodySizeGetter is optionally implemented by getters that
+// see the decoded response body to record the
+// http.response.body.uncompressed_size attribute. The size is -1 when unknown
+// or when the body was not decoded.
+type HTTPUncompressedBodySizeGetter[REQUEST any, RESPONSE any] interface {
+	GetHTTPResponseUncompressedBodySize(request REQUEST, response RESPONSE) int64
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/utils"
+)
+
+const (
+	CorrelationIDKey = attribute.Key("correlation.id")
+
+	DefaultCorrelationIDHeader = "X-Correlation-ID"
+)
+
+// CorrelationIDExtractor reads a correlation ID from a request header and
+// records it as the correlation.id attribute. The ID is stored in the context
+// so that downstream spans without the header carry the same attribute. It
+// accepts both client and server getters.
+type CorrelationIDExtractor[REQUEST HTTPRequest, RESPONSE HTTPResponse,
+	COMMONATTRGETTER HTTPCommonAttrsGetter[REQUEST, RESPONSE]] struct {
+	HTTPGetter COMMONATTRGETTER
+	// HeaderName defaults to DefaultCorrelationIDHeader when empty
+	HeaderName string
+}
+
+func (c *CorrelationIDExtractor[REQUEST, RESPONSE, COMMONATTRGETTER]) OnStart(parentContext context.Context,
+	attributes []attribute.KeyValue,
+	request REQUEST,
+) ([]attribute.KeyValue, context.Context) {
+	headerName := c.HeaderName
+	if headerName == "" {
+		headerName = DefaultCorrelationIDHeader
+	}
+	id := ""
+	if values := c.HTTPGetter.GetHTTPRequestHeader(request, headerName); len(values) > 0 {
+		id = values[0]
+	}
+	if id == "" {
+		id = CorrelationIDFromContext(parentContext)
+	} else {
+		parentContext = ContextWithCorrelationID(parentContext, id)
+	}
+	if id != "" {
+		attributes = append(attributes, CorrelationIDKey.String(id))
+	}
+	return attributes, parentContext
+}
+
+func (c *CorrelationIDExtractor[REQUEST, RESPONSE, COMMONATTRGETTER]) OnEnd(context context.Context,
+	attributes []attribute.KeyValue,
+	request REQUEST, response RESPONSE, err error,
+) ([]attribute.KeyValue, context.Context) {
+	return attributes, context
+}
+
+// ContextWithCorrelationID returns a copy of ctx carrying the correlation ID
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, utils.CorrelationIDContextKey, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID stored in ctx, if any
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(utils.CorrelationIDContextKey).(string)
+	return id
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type correlationServerGetter struct {
+	httpServerAttrsGetter
+	headers map[string][]string
+}
+
+func (c correlationServerGetter) GetHTTPRequestHeader(_ testRequest, name string) []string {
+	return c.headers[name]
+}
+
+type correlationClientGetter struct {
+	httpClientAttrsGetter
+}
+
+func (correlationClientGetter) GetHTTPRequestHeader(_ testRequest, _ string) []string {
+	return nil
+}
+
+func TestCorrelationIDExtractorWithHeader(t *testing.T) {
+	extractor := CorrelationIDExtractor[testRequest, testResponse, correlationServerGetter]{
+		HTTPGetter: correlationServerGetter{headers: map[string][]string{"X-Request-Tag": {"abc-123"}}},
+		HeaderName: "X-Request-Tag",
+	}
+	attrs, ctx := extractor.OnStart(context.Background(), nil, testRequest{})
+	if len(attrs) != 1 || attrs[0].Key != CorrelationIDKey || attrs[0].Value.AsString() != "abc-123" {
+		t.Fatalf("correlation id should be abc-123, got %v", attrs)
+	}
+	if CorrelationIDFromContext(ctx) != "abc-123" {
+		t.Fatal("correlation id should be stored in context")
+	}
+
+	// A downstream client span without the header inherits the ID
+	clientExtractor := CorrelationIDExtractor[testRequest, testResponse, correlationClientGetter]{}
+	attrs, _ = clientExtractor.OnStart(ctx, nil, testRequest{})
+	if len(attrs) != 1 || attrs[0].Value.AsString() != "abc-123" {
+		t.Fatalf("downstream span should carry the correlation id, got %v", attrs)
+	}
+}
+
+func TestCorrelationIDExtractorWithoutHeader(t *testing.T) {
+	extractor := CorrelationIDExtractor[testRequest, testResponse, correlationServerGetter]{
+		HTTPGetter: correlationServerGetter{headers: map[string][]string{}},
+	}
+	parentContext := context.Background()
+	attrs, ctx := extractor.OnStart(parentContext, []attribute.KeyValue{}, testRequest{})
+	if len(attrs) != 0 {
+		t.Fatalf("no attribute expected, got %v", attrs)
+	}
+	if ctx != parentContext {
+		t.Fatal("context should be untouched")
+	}
+	attrs, _ = extractor.OnEnd(ctx, attrs, testRequest{}, testResponse{}, nil)
+	if len(attrs) != 0 {
+		t.Fatalf("no attribute expected on end, got %v", attrs)
+	}
+}
+
+func TestCorrelationIDExtractorDefaultHeader(t *testing.T) {
+	extractor := CorrelationIDExtractor[testRequest, testResponse, correlationServerGetter]{
+		HTTPGetter: correlationServerGetter{headers: map[string][]string{DefaultCorrelationIDHeader: {"id-1"}}},
+	}
+	attrs, _ := extractor.OnStart(context.Background(), nil, testRequest{})
+	if len(attrs) != 1 || attrs[0].Value.AsString() != "id-1" {
+		t.Fatalf("correlation id should be read from the default header, got %v", attrs)
+	}
+}
@@ -24,8 +24,10 @@ Here are some implementations for stable metrics.
 */
 
 const (
-	httpDerverTequestDuration = "http.server.request.duration"
-	httpClientRequestDuration = "http.client.request.duration"
+	httpDerverTequestDuration  = "http.server.request.duration"
+	httpClientRequestDuration  = "http.client.request.duration"
+	httpServerRequestBodySize  = "http.server.request.body.size"
+	httpServerResponseBodySize = "http.server.response.body.size"
 )
 
 // httpMetricsConv defines the attributes that should be included in HTTP metrics
@@ -44,6 +46,22 @@ var httpMetricsConv = map[attribute.Key]bool{
 	semconv.ServerPortKey:             true,
 }
 
+// ephemeralPortStart starts the IANA dynamic port range. Such ports are
+// usually picked per process, e.g. by test servers or port forwarding, and
+// would split the metrics of a single upstream
+const ephemeralPortStart = 49152
+
+// withoutEphemeralPort drops server.port from the metric attributes when the
+// port is ephemeral, keeping client metrics bounded per server.address
+func withoutEphemeralPort(attrs []attribute.KeyValue) []attribute.KeyValue {
+	for i, attr := range attrs {
+		if attr.Key == semconv.ServerPortKey && attr.Value.AsInt64() >= ephemeralPortStart {
+			return append(attrs[:i:i], attrs[i+1:]...)
+		}
+	}
+	return attrs
+}
+
 // Registry is the interface for creating HTTP metrics
 type Registry interface {
 	// NewHTTPServerMetric creates a new HTTP server metric
@@ -54,9 +72,10 @@ type Registry interface {
 
 // MetricsRegistry manages HTTP metrics creation and configuration
 type MetricsRegistry struct {
-	logger *slog.Logger
-	meter  metric.Meter
-	mu     sync.RWMutex
+	logger    *slog.Logger
+	meter     metric.Meter
+	attrsConv map[attribute.Key]bool
+	mu        sync.RWMutex
 }
 
 // NewMetricsRegistry creates a new MetricsRegistry with the given logger and meter
@@ -73,7 +92,10 @@ func NewMetricsRegistry(logger *slog.Logger, meter metric.Meter) *MetricsRegistr
 // HTTPServerMetric represents HTTP server metrics
 type HTTPServerMetric struct {
 	key                   attribute.Key
+	attrsConv             map[attribute.Key]bool
 	serverRequestDuration metric.Float64Histogram
+	requestBodySize       metric.Int64Histogram
+	responseBodySize      metric.Int64Histogram
 	logger                *slog.Logger
 	mu                    sync.Mutex
 }
@@ -81,11 +103,33 @@ type HTTPServerMetric struct {
 // HTTPClientMetric represents HTTP client metrics
 type HTTPClientMetric struct {
 	key                   attribute.Key
+	attrsConv             map[attribute.Key]bool
 	clientRequestDuration metric.Float64Histogram
 	logger                *slog.Logger
 	mu                    sync.Mutex
 }
 
+// SetAttributeKeys restricts the attributes of the metrics created afterwards
+// to keys, e.g. to the method and status code for backends that cannot afford
+// more series. Only semconv HTTP metric attributes are recorded, other keys are
+// ignored. Spans keep all their attributes. Passing no key restores the
+// default semconv set.
+func (r *MetricsRegistry) SetAttributeKeys(keys ...attribute.Key) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(keys) == 0 {
+		r.attrsConv = nil
+		return
+	}
+	r.attrsConv = make(map[attribute.Key]bool, len(keys))
+	for _, key := range keys {
+		if httpMetricsConv[key] {
+			r.attrsConv[key] = true
+		}
+	}
+}
+
 // NewHTTPServerMetric creates a new HTTP server metric
 func (r *MetricsRegistry) NewHTTPServerMetric(key string) (*HTTPServerMetric, error) {
 	r.mu.RLock()
@@ -96,8 +140,9 @@ func (r *MetricsRegistry) NewHTTPServerMetric(key string) (*HTTPServerMetric, er
 	}
 
 	m := &HTTPServerMetric{
-		key:    attribute.Key(key),
-		logger: r.logger,
+		key:       attribute.Key(key),
+		attrsConv: r.attrsConv,
+		logger:    r.logger,
 	}
 
 	// Eagerly create the histogram if meter is available
@@ -106,6 +151,16 @@ func (r *MetricsRegistry) NewHTTPServerMetric(key string) (*HTTPServerMetric, er
 		return nil, fmt.Errorf("failed to create serverRequestDuration: %w", err)
 	}
 	m.serverRequestDuration = d
+	m.requestBodySize, err = utils.NewInt64Histogram(httpServerRequestBodySize, "By",
+		"Size of HTTP server request bodies.", r.meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create requestBodySize: %w", err)
+	}
+	m.responseBodySize, err = utils.NewInt64Histogram(httpServerResponseBodySize, "By",
+		"Size of HTTP server response bodies.", r.meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create responseBodySize: %w", err)
+	}
 
 	return m, nil
 }
@@ -120,8 +175,9 @@ func (r *MetricsRegistry) NewHTTPClientMetric(key string) (*HTTPClientMetric, er
 	}
 
 	m := &HTTPClientMetric{
-		key:    attribute.Key(key),
-		logger: r.logger,
+		key:       attribute.Key(key),
+		attrsConv: r.attrsConv,
+		logger:    r.logger,
 	}
 
 	// Eagerly create the histogram if meter is available
@@ -163,6 +219,15 @@ type httpMetricContext struct {
 	startAttributes []attribute.KeyValue
 }
 
+// metricAttrsConv returns the attributes recorded by the metric, the semconv
+// ones unless restricted by MetricsRegistry.SetAttributeKeys
+func (h *HTTPServerMetric) metricAttrsConv() map[attribute.Key]bool {
+	if h.attrsConv == nil {
+		return httpMetricsConv
+	}
+	return h.attrsConv
+}
+
 func (*HTTPServerMetric) OnBeforeStart(parentContext context.Context, _ time.Time) context.Context {
 	return parentContext
 }
@@ -180,6 +245,9 @@ func (h *HTTPServerMetric) OnBeforeEnd(
 
 func (*HTTPServerMetric) OnAfterStart(_ context.Context, _ time.Time) {}
 
+// RecordsMetrics tags the listener as a metric one, see MetricsDisabled
+func (*HTTPServerMetric) RecordsMetrics() {}
+
 func (h *HTTPServerMetric) OnAfterEnd(context context.Context, endAttributes []attribute.KeyValue, endTime time.Time) {
 	value := context.Value(h.key)
 	if value == nil {
@@ -205,12 +273,27 @@ func (h *HTTPServerMetric) OnAfterEnd(context context.Context, endAttributes []a
 	}
 
 	endAttributes = append(endAttributes, startAttributes...)
-	n, metricsAttrs := utils.Shadow(endAttributes, httpMetricsConv)
-	h.serverRequestDuration.Record(
-		context,
-		float64(endTime.Sub(startTime)),
-		metric.WithAttributeSet(attribute.NewSet(metricsAttrs[0:n]...)),
-	)
+	n, metricsAttrs := utils.Shadow(endAttributes, h.metricAttrsConv())
+	// The context still carries the span, so the SDK can attach the trace as
+	// an exemplar of this measurement
+	attrSet := metric.WithAttributeSet(attribute.NewSet(metricsAttrs[0:n]...))
+	h.serverRequestDuration.Record(context, float64(endTime.Sub(startTime)), attrSet)
+	// Body sizes are only present when known, see HTTPBodySizeGetter
+	for _, attr := range endAttributes {
+		switch {
+		case attr.Key == semconv.HTTPRequestBodySizeKey && h.requestBodySize != nil:
+			h.requestBodySize.Record(context, attr.Value.AsInt64(), attrSet)
+		case attr.Key == semconv.HTTPResponseBodySizeKey && h.responseBodySize != nil:
+			h.responseBodySize.Record(context, attr.Value.AsInt64(), attrSet)
+		}
+	}
+}
+
+func (h *HTTPClientMetric) metricAttrsConv() map[attribute.Key]bool {
+	if h.attrsConv == nil {
+		return httpMetricsConv
+	}
+	return h.attrsConv
 }
 
 func (*HTTPClientMetric) OnBeforeStart(parentContext context.Context, _ time.Time) context.Context {
@@ -230,6 +313,9 @@ func (h *HTTPClientMetric) OnBeforeEnd(
 
 func (*HTTPClientMetric) OnAfterStart(_ context.Context, _ time.Time) {}
 
+// RecordsMetrics tags the listener as a metric one, see MetricsDisabled
+func (*HTTPClientMetric) RecordsMetrics() {}
+
 func (h *HTTPClientMetric) OnAfterEnd(context context.Context, endAttributes []attribute.KeyValue, endTime time.Time) {
 	value := context.Value(h.key)
 	if value == nil {
@@ -255,10 +341,12 @@ func (h *HTTPClientMetric) OnAfterEnd(context context.Context, endAttributes []a
 	}
 
 	endAttributes = append(endAttributes, startAttributes...)
-	n, metricsAttrs := utils.Shadow(endAttributes, httpMetricsConv)
+	n, metricsAttrs := utils.Shadow(endAttributes, h.metricAttrsConv())
+	// The context still carries the span, so the SDK can attach the trace as
+	// an exemplar of this measurement
 	h.clientRequestDuration.Record(
 		context,
 		float64(endTime.Sub(startTime)),
-		metric.WithAttributeSet(attribute.NewSet(metricsAttrs[0:n]...)),
+		metric.WithAttributeSet(attribute.NewSet(withoutEphemeralPort(metricsAttrs[0:n])...)),
 	)
 }
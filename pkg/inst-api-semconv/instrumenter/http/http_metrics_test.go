@@ -7,6 +7,7 @@ package http
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"testing"
 	"time"
 
@@ -15,10 +16,13 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
 
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/utils"
 )
 
@@ -77,6 +81,63 @@ func TestHTTPClientMetrics(t *testing.T) {
 	assert.Equal(t, "http.client.request.duration", rm.ScopeMetrics[0].Metrics[0].Name)
 }
 
+func TestHTTPServerMetricsExemplar(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
+	)
+	server, err := newHTTPServerMetric("test", mp.Meter("test-meter"))
+	require.NoError(t, err)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	ctx, span := tp.Tracer("test-tracer").Start(context.Background(), "request")
+	start := time.Now()
+	ctx = server.OnBeforeStart(ctx, start)
+	ctx = server.OnBeforeEnd(ctx, []attribute.KeyValue{}, start)
+	server.OnAfterStart(ctx, start)
+	span.End()
+	server.OnAfterEnd(ctx, []attribute.KeyValue{}, time.Now())
+
+	rm := &metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(ctx, rm))
+	hist, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, hist.DataPoints, 1)
+	exemplars := hist.DataPoints[0].Exemplars
+	require.Len(t, exemplars, 1)
+	traceID := span.SpanContext().TraceID()
+	assert.Equal(t, traceID[:], exemplars[0].TraceID)
+}
+
+func TestHTTPClientMetricsExemplar(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
+	)
+	client, err := newHTTPClientMetric("test", mp.Meter("test-meter"))
+	require.NoError(t, err)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	ctx, span := tp.Tracer("test-tracer").Start(context.Background(), "request")
+	start := time.Now()
+	ctx = client.OnBeforeStart(ctx, start)
+	ctx = client.OnBeforeEnd(ctx, []attribute.KeyValue{}, start)
+	client.OnAfterStart(ctx, start)
+	span.End()
+	client.OnAfterEnd(ctx, []attribute.KeyValue{}, time.Now())
+
+	rm := &metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(ctx, rm))
+	hist, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, hist.DataPoints, 1)
+	exemplars := hist.DataPoints[0].Exemplars
+	require.Len(t, exemplars, 1)
+	spanID := span.SpanContext().SpanID()
+	assert.Equal(t, spanID[:], exemplars[0].SpanID)
+	assert.True(t, span.SpanContext().TraceID().IsValid())
+}
+
 func TestHTTPMetricAttributesShadower(t *testing.T) {
 	attrs := make([]attribute.KeyValue, 0)
 	attrs = append(attrs, attribute.KeyValue{
@@ -97,6 +158,93 @@ func TestHTTPMetricAttributesShadower(t *testing.T) {
 	assert.Equal(t, attribute.Key("unknown"), attrs[n].Key)
 }
 
+func TestHTTPClientMetricsServerAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     int
+		wantPort bool
+	}{
+		{name: "well-known port", port: 443, wantPort: true},
+		{name: "registered port", port: 8080, wantPort: true},
+		{name: "ephemeral port", port: 54321, wantPort: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := sdkmetric.NewManualReader()
+			mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+			client, err := newHTTPClientMetric("test", mp.Meter("test-meter"))
+			require.NoError(t, err)
+			ctx := context.Background()
+			start := time.Now()
+			startAttrs := []attribute.KeyValue{
+				semconv.HTTPRequestMethodKey.String("GET"),
+				semconv.ServerAddress("example.com"),
+				semconv.ServerPort(tt.port),
+				semconv.URLPath("/users/42"),
+			}
+			ctx = client.OnBeforeEnd(ctx, startAttrs, start)
+			client.OnAfterEnd(ctx, []attribute.KeyValue{semconv.HTTPResponseStatusCode(200)}, time.Now())
+
+			rm := &metricdata.ResourceMetrics{}
+			require.NoError(t, reader.Collect(ctx, rm))
+			hist, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+			require.True(t, ok)
+			require.Len(t, hist.DataPoints, 1)
+			attrs := hist.DataPoints[0].Attributes
+			address, ok := attrs.Value(semconv.ServerAddressKey)
+			require.True(t, ok)
+			assert.Equal(t, "example.com", address.AsString())
+			port, ok := attrs.Value(semconv.ServerPortKey)
+			require.Equal(t, tt.wantPort, ok)
+			if ok {
+				assert.Equal(t, int64(tt.port), port.AsInt64())
+			}
+			_, ok = attrs.Value(semconv.URLPathKey)
+			assert.False(t, ok, "the path is not a metric attribute")
+		})
+	}
+}
+
+func TestMetricsRegistryAttributeKeys(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	registry := NewMetricsRegistry(slog.Default(), mp.Meter("test-meter"))
+	// Non semconv keys cannot be added to the metrics
+	registry.SetAttributeKeys(semconv.HTTPRequestMethodKey, semconv.HTTPResponseStatusCodeKey, semconv.URLPathKey)
+	server, err := registry.NewHTTPServerMetric("server")
+	require.NoError(t, err)
+	client, err := registry.NewHTTPClientMetric("client")
+	require.NoError(t, err)
+
+	startAttrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String("GET"),
+		semconv.URLScheme("http"),
+		semconv.URLPath("/users/42"),
+		semconv.ServerAddress("example.com"),
+	}
+	endAttrs := []attribute.KeyValue{
+		semconv.HTTPResponseStatusCode(200),
+		semconv.HTTPRoute("/users/{id}"),
+	}
+	ctx := context.Background()
+	start := time.Now()
+	for _, listener := range []instrumenter.OperationListener{server, client} {
+		ctx := listener.OnBeforeEnd(ctx, slices.Clone(startAttrs), start)
+		listener.OnAfterEnd(ctx, slices.Clone(endAttrs), time.Now())
+	}
+
+	rm := &metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(ctx, rm))
+	want := attribute.NewSet(semconv.HTTPRequestMethodKey.String("GET"), semconv.HTTPResponseStatusCode(200))
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 2)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		hist, ok := m.Data.(metricdata.Histogram[float64])
+		require.True(t, ok)
+		require.Len(t, hist.DataPoints, 1)
+		assert.Equal(t, want, hist.DataPoints[0].Attributes, m.Name)
+	}
+}
+
 // Tests for MetricsRegistry API
 func TestMetricsRegistryHTTPServerMetrics(t *testing.T) {
 	reader := sdkmetric.NewManualReader()
@@ -244,3 +392,122 @@ func TestRegistryInterface(t *testing.T) {
 	// Test with NoOpRegistry
 	testRegistry(NewNoOpRegistry())
 }
+
+type metricsSpanNameExtractor struct{}
+
+func (metricsSpanNameExtractor) Extract(_ testRequest) string {
+	return "GET"
+}
+
+func TestHTTPServerMetricsDisabled(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	server, err := newHTTPServerMetric("test", mp.Meter("test-meter"))
+	require.NoError(t, err)
+	tp := sdktrace.NewTracerProvider()
+
+	for _, disabled := range []bool{true, false} {
+		builder := instrumenter.Builder[testRequest, testResponse]{}
+		builder.Init().
+			SetSpanNameExtractor(metricsSpanNameExtractor{}).
+			SetSpanKindExtractor(&instrumenter.AlwaysServerExtractor[testRequest]{}).
+			AddOperationListeners(server).
+			SetMetricsDisabled(disabled)
+		inst := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+		ctx := inst.Start(context.Background(), testRequest{})
+		inst.End(ctx, instrumenter.Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+
+		rm := &metricdata.ResourceMetrics{}
+		require.NoError(t, reader.Collect(context.Background(), rm))
+		if disabled {
+			assert.Empty(t, rm.ScopeMetrics, "no metric expected when metrics are disabled")
+		} else {
+			require.Len(t, rm.ScopeMetrics, 1)
+			assert.Equal(t, "http.server.request.duration", rm.ScopeMetrics[0].Metrics[0].Name)
+		}
+	}
+}
+
+// fakeClock advances by step on every reading
+type fakeClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (f *fakeClock) Now() time.Time {
+	now := f.now
+	f.now = f.now.Add(f.step)
+	return now
+}
+
+func TestHTTPServerMetricsWithFakeClock(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	server, err := newHTTPServerMetric("test", mp.Meter("test-meter"))
+	require.NoError(t, err)
+	tp := sdktrace.NewTracerProvider()
+	clock := &fakeClock{now: time.Unix(0, 0), step: 250 * time.Millisecond}
+
+	builder := instrumenter.Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(metricsSpanNameExtractor{}).
+		SetSpanKindExtractor(&instrumenter.AlwaysServerExtractor[testRequest]{}).
+		AddOperationListeners(server).
+		SetClock(clock)
+	inst := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+	ctx := inst.Start(context.Background(), testRequest{})
+	inst.End(ctx, instrumenter.Invocation[testRequest, testResponse]{})
+
+	rm := &metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(context.Background(), rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	histogram, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, histogram.DataPoints, 1)
+	assert.Equal(t, uint64(1), histogram.DataPoints[0].Count)
+	assert.Equal(t, float64(250*time.Millisecond), histogram.DataPoints[0].Sum)
+}
+
+func TestHTTPServerBodySizeMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	server, err := newHTTPServerMetric("test", mp.Meter("test-meter"))
+	require.NoError(t, err)
+	requests := [][]attribute.KeyValue{
+		{
+			semconv.HTTPRequestMethodKey.String("POST"),
+			semconv.HTTPResponseStatusCodeKey.Int(201),
+			semconv.HTTPRequestBodySizeKey.Int(128),
+			semconv.HTTPResponseBodySizeKey.Int(512),
+		},
+		// Unknown sizes are not recorded as attributes
+		{
+			semconv.HTTPRequestMethodKey.String("GET"),
+			semconv.HTTPResponseStatusCodeKey.Int(200),
+		},
+	}
+	for _, attrs := range requests {
+		ctx := context.Background()
+		start := time.Now()
+		ctx = server.OnBeforeEnd(ctx, nil, start)
+		server.OnAfterEnd(ctx, attrs, start.Add(time.Millisecond))
+	}
+
+	rm := &metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(context.Background(), rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	sums := make(map[string][]int64)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		histogram, ok := m.Data.(metricdata.Histogram[int64])
+		if !ok {
+			continue
+		}
+		for _, dp := range histogram.DataPoints {
+			method, _ := dp.Attributes.Value(semconv.HTTPRequestMethodKey)
+			assert.Equal(t, "POST", method.AsString())
+			sums[m.Name] = append(sums[m.Name], dp.Sum)
+		}
+	}
+	assert.Equal(t, []int64{128}, sums["http.server.request.body.size"])
+	assert.Equal(t, []int64{512}, sums["http.server.response.body.size"])
+}
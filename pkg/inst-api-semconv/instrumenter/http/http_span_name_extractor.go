@@ -12,6 +12,11 @@ const defaultHTTPSpanName = "HTTP"
 
 type HTTPClientSpanNameExtractor[REQUEST any, RESPONSE any] struct {
 	Getter HTTPClientAttrsGetter[REQUEST, RESPONSE]
+	// WithServerAddress names spans {method} {server.address} when the getter
+	// implements HTTPServerAddressGetter, e.g. "GET example.com", to tell calls
+	// to different hosts apart. The path is never included as it is not
+	// low-cardinality.
+	WithServerAddress bool
 }
 
 func (h *HTTPClientSpanNameExtractor[REQUEST, RESPONSE]) Extract(request REQUEST) string {
@@ -19,7 +24,18 @@ func (h *HTTPClientSpanNameExtractor[REQUEST, RESPONSE]) Extract(request REQUEST
 	if method == "" {
 		return defaultHTTPSpanName
 	}
-	return method
+	if !h.WithServerAddress {
+		return method
+	}
+	getter, ok := h.Getter.(HTTPServerAddressGetter[REQUEST])
+	if !ok {
+		return method
+	}
+	address := getter.GetServerAddress(request)
+	if address == "" {
+		return method
+	}
+	return method + " " + address
 }
 
 type HTTPServerSpanNameExtractor[REQUEST any, RESPONSE any] struct {
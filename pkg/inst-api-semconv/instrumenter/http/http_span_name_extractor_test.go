@@ -53,6 +53,38 @@ func TestHTTPClientExtractSpanName(t *testing.T) {
 	}
 }
 
+type addressClientGetter struct {
+	testClientGetter
+}
+
+func (addressClientGetter) GetServerAddress(_ testRequest) string {
+	return "example.com"
+}
+
+func TestHTTPClientExtractSpanNameWithServerAddress(t *testing.T) {
+	tests := []struct {
+		name              string
+		getter            HTTPClientAttrsGetter[testRequest, testResponse]
+		withServerAddress bool
+		want              string
+	}{
+		{name: "method only", getter: addressClientGetter{}, want: "GET"},
+		{name: "with server address", getter: addressClientGetter{}, withServerAddress: true, want: "GET example.com"},
+		{name: "no address getter", getter: testClientGetter{}, withServerAddress: true, want: "GET"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := HTTPClientSpanNameExtractor[testRequest, testResponse]{
+				Getter:            tt.getter,
+				WithServerAddress: tt.withServerAddress,
+			}
+			if spanName := r.Extract(testRequest{Method: "GET", Route: "/users/42"}); spanName != tt.want {
+				t.Errorf("want %s, got %s", tt.want, spanName)
+			}
+		})
+	}
+}
+
 func TestHTTPServerExtractSpanName(t *testing.T) {
 	r := HTTPServerSpanNameExtractor[testRequest, testResponse]{Getter: testServerGetter{}}
 	spanName := r.Extract(testRequest{Method: "GET"})
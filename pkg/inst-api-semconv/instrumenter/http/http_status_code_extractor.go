@@ -19,8 +19,15 @@ For HTTPClient, status code >= 400 or < 100 is treated as error.
 
 const invalidHTTPStatusCode = "INVALID_HTTP_STATUS_CODE"
 
+// SpanStatusMapper decides the span status and its description from the
+// response status code and the error of the request.
+type SpanStatusMapper func(statusCode int, err error) (codes.Code, string)
+
 type HTTPClientSpanStatusExtractor[REQUEST any, RESPONSE any] struct {
 	Getter HTTPCommonAttrsGetter[REQUEST, RESPONSE]
+	// StatusMapper overrides the span status when set, the semconv rules
+	// above apply otherwise
+	StatusMapper SpanStatusMapper
 }
 
 func (h HTTPClientSpanStatusExtractor[REQUEST, RESPONSE]) Extract(
@@ -30,6 +37,10 @@ func (h HTTPClientSpanStatusExtractor[REQUEST, RESPONSE]) Extract(
 	err error,
 ) {
 	statusCode := h.Getter.GetHTTPResponseStatusCode(request, response, err)
+	if h.StatusMapper != nil {
+		extractMappedStatus(span, h.StatusMapper, statusCode, err)
+		return
+	}
 	if statusCode >= 400 || statusCode < 100 {
 		if err != nil {
 			span.RecordError(err)
@@ -47,6 +58,9 @@ func (h HTTPClientSpanStatusExtractor[REQUEST, RESPONSE]) Extract(
 
 type HTTPServerSpanStatusExtractor[REQUEST any, RESPONSE any] struct {
 	Getter HTTPCommonAttrsGetter[REQUEST, RESPONSE]
+	// StatusMapper overrides the span status when set, the semconv rules
+	// above apply otherwise
+	StatusMapper SpanStatusMapper
 }
 
 func (h HTTPServerSpanStatusExtractor[REQUEST, RESPONSE]) Extract(
@@ -56,6 +70,10 @@ func (h HTTPServerSpanStatusExtractor[REQUEST, RESPONSE]) Extract(
 	err error,
 ) {
 	statusCode := h.Getter.GetHTTPResponseStatusCode(request, response, err)
+	if h.StatusMapper != nil {
+		extractMappedStatus(span, h.StatusMapper, statusCode, err)
+		return
+	}
 	if statusCode >= 500 || statusCode < 100 {
 		if err != nil {
 			span.RecordError(err)
@@ -70,3 +88,18 @@ func (h HTTPServerSpanStatusExtractor[REQUEST, RESPONSE]) Extract(
 		span.SetStatus(codes.Ok, "success")
 	}
 }
+
+func extractMappedStatus(span trace.Span, mapper SpanStatusMapper, statusCode int, err error) {
+	code, description := mapper(statusCode, err)
+	if code != codes.Error {
+		span.SetStatus(code, description)
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.SetStatus(code, description)
+	span.SetAttributes(
+		attribute.KeyValue{Key: semconv.ErrorTypeKey, Value: attribute.StringValue(strconv.Itoa(statusCode))},
+	)
+}
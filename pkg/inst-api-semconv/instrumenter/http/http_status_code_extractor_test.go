@@ -184,3 +184,35 @@ func TestHTTPServerSpanStatusExtractor201(t *testing.T) {
 		t.Fatal("span status should be ok!")
 	}
 }
+
+func TestHTTPServerSpanStatusExtractorMapper(t *testing.T) {
+	// Flips the default, 404 is an error and 500 is not
+	mapper := func(statusCode int, _ error) (codes.Code, string) {
+		if statusCode == 404 {
+			return codes.Error, "not found"
+		}
+		return codes.Unset, ""
+	}
+	tests := []struct {
+		code   int
+		status codes.Code
+	}{
+		{code: 404, status: codes.Error},
+		{code: 500, status: codes.Unset},
+	}
+	for _, tt := range tests {
+		c := HTTPServerSpanStatusExtractor[any, any]{
+			Getter:       customizedNetHTTPAttrsGetter{code: tt.code},
+			StatusMapper: mapper,
+		}
+		u := codes.Code(0)
+		span := &testSpan{status: &u}
+		c.Extract(span, nil, nil, nil)
+		if *span.status != tt.status {
+			t.Fatalf("span status of %d should be %s, got %s", tt.code, tt.status, *span.status)
+		}
+		if tt.status == codes.Error && span.Kvs == nil {
+			t.Fatal("kv should not be nil")
+		}
+	}
+}
@@ -5,6 +5,8 @@ package net
 
 import (
 	"context"
+	"net/url"
+	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
@@ -40,8 +42,25 @@ func CreateNetworkAttributesExtractor[REQUEST, RESPONSE any](
 
 type URLAttrsExtractor[REQUEST any, RESPONSE any, GETTER URLAttrsGetter[REQUEST]] struct {
 	Getter GETTER
+	// CapturedQueryParams lists the query parameters recorded individually as
+	// url.query.<name> attributes. When set, the values of all other
+	// parameters are redacted in url.query.
+	CapturedQueryParams []string
+	// RecordQueryShape records the number of query parameters and the length
+	// of the query as url.query.param_count and url.query.length instead of
+	// url.query, so that no value is recorded unless allowlisted in
+	// CapturedQueryParams.
+	RecordQueryShape bool
 }
 
+const (
+	urlQueryParamPrefix = "url.query."
+	redactedValue       = "REDACTED"
+
+	URLQueryParamCountKey = attribute.Key("url.query.param_count")
+	URLQueryLengthKey     = attribute.Key("url.query.length")
+)
+
 func (u *URLAttrsExtractor[REQUEST, RESPONSE, GETTER]) OnStart(parentContext context.Context,
 	attributes []attribute.KeyValue, request REQUEST,
 ) ([]attribute.KeyValue, context.Context) {
@@ -51,11 +70,86 @@ func (u *URLAttrsExtractor[REQUEST, RESPONSE, GETTER]) OnStart(parentContext con
 	}, attribute.KeyValue{
 		Key:   semconv.URLPathKey,
 		Value: attribute.StringValue(u.Getter.GetURLPath(request)),
-	}, attribute.KeyValue{
+	})
+	query := u.Getter.GetURLQuery(request)
+	if u.RecordQueryShape {
+		attributes = append(attributes,
+			URLQueryParamCountKey.Int(countQueryParams(query)),
+			URLQueryLengthKey.Int(len(query)))
+		_, params := u.captureQueryParams(query)
+		return append(attributes, params...), parentContext
+	}
+	if len(u.CapturedQueryParams) == 0 {
+		attributes = append(attributes, attribute.KeyValue{
+			Key:   semconv.URLQueryKey,
+			Value: attribute.StringValue(query),
+		})
+		return attributes, parentContext
+	}
+	redacted, params := u.captureQueryParams(query)
+	attributes = append(attributes, attribute.KeyValue{
 		Key:   semconv.URLQueryKey,
-		Value: attribute.StringValue(u.Getter.GetURLQuery(request)),
+		Value: attribute.StringValue(redacted),
 	})
-	return attributes, parentContext
+	return append(attributes, params...), parentContext
+}
+
+// countQueryParams counts the parameters of query, repeated ones once per
+// occurrence
+func countQueryParams(query string) int {
+	count := 0
+	for _, pair := range strings.Split(query, "&") {
+		if pair != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// captureQueryParams returns the query with the values of the parameters that
+// are not allowlisted redacted, and the attributes of the allowlisted ones,
+// repeated ones as an array
+func (u *URLAttrsExtractor[REQUEST, RESPONSE, GETTER]) captureQueryParams(query string,
+) (string, []attribute.KeyValue) {
+	captured := make(map[string][]string, len(u.CapturedQueryParams))
+	for _, name := range u.CapturedQueryParams {
+		captured[name] = nil
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue, hasValue := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		values, ok := captured[key]
+		if !ok {
+			if hasValue {
+				pairs[i] = rawKey + "=" + redactedValue
+			}
+			continue
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			value = rawValue
+		}
+		captured[key] = append(values, value)
+	}
+	var attributes []attribute.KeyValue
+	for _, name := range u.CapturedQueryParams {
+		values := captured[name]
+		switch len(values) {
+		case 0:
+		case 1:
+			attributes = append(attributes, attribute.String(urlQueryParamPrefix+name, values[0]))
+		default:
+			attributes = append(attributes, attribute.StringSlice(urlQueryParamPrefix+name, values))
+		}
+	}
+	return strings.Join(pairs, "&"), attributes
 }
 
 func (_ *URLAttrsExtractor[REQUEST, RESPONSE, GETTER]) OnEnd(context context.Context,
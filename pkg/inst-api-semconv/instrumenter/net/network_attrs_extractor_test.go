@@ -46,3 +46,60 @@ func TestOnStart(t *testing.T) {
 	assert.Equal(t, expectedAttributes, resultAttributes)
 	assert.Equal(t, parentContext, resultContext)
 }
+
+type queryURLGetter struct {
+	MockURLGetter
+	query string
+}
+
+func (q *queryURLGetter) GetURLQuery(_ any) string {
+	return q.query
+}
+
+func TestOnStartCapturesQueryParams(t *testing.T) {
+	urlExtractor := &URLAttrsExtractor[any, any, *queryURLGetter]{
+		Getter:              &queryURLGetter{query: "page=2&token=secret"},
+		CapturedQueryParams: []string{"page", "limit"},
+	}
+	resultAttributes, _ := urlExtractor.OnStart(context.Background(), nil, nil)
+	expectedAttributes := []attribute.KeyValue{
+		attribute.String(string(semconv.URLSchemeKey), "http"),
+		attribute.String(string(semconv.URLPathKey), "/test"),
+		attribute.String(string(semconv.URLQueryKey), "page=2&token=REDACTED"),
+		attribute.String("url.query.page", "2"),
+	}
+	assert.Equal(t, expectedAttributes, resultAttributes)
+}
+
+func TestOnStartCapturesRepeatedQueryParams(t *testing.T) {
+	urlExtractor := &URLAttrsExtractor[any, any, *queryURLGetter]{
+		Getter:              &queryURLGetter{query: "tag=a&tag=b%20c&flag"},
+		CapturedQueryParams: []string{"tag"},
+	}
+	resultAttributes, _ := urlExtractor.OnStart(context.Background(), nil, nil)
+	assert.Contains(t, resultAttributes, attribute.StringSlice("url.query.tag", []string{"a", "b c"}))
+	assert.Contains(t, resultAttributes, attribute.String(string(semconv.URLQueryKey), "tag=a&tag=b%20c&flag"))
+}
+
+func TestOnStartRecordsQueryShape(t *testing.T) {
+	urlExtractor := &URLAttrsExtractor[any, any, *queryURLGetter]{
+		Getter:           &queryURLGetter{query: "page=2&token=secret&tag=a&tag=b"},
+		RecordQueryShape: true,
+	}
+	resultAttributes, _ := urlExtractor.OnStart(context.Background(), nil, nil)
+	expectedAttributes := []attribute.KeyValue{
+		attribute.String(string(semconv.URLSchemeKey), "http"),
+		attribute.String(string(semconv.URLPathKey), "/test"),
+		URLQueryParamCountKey.Int(4),
+		URLQueryLengthKey.Int(31),
+	}
+	assert.Equal(t, expectedAttributes, resultAttributes)
+
+	// Allowlisted parameters are still recorded, the query itself is not
+	urlExtractor.CapturedQueryParams = []string{"page"}
+	resultAttributes, _ = urlExtractor.OnStart(context.Background(), nil, nil)
+	assert.Contains(t, resultAttributes, attribute.String("url.query.page", "2"))
+	for _, attr := range resultAttributes {
+		assert.NotEqual(t, semconv.URLQueryKey, attr.Key)
+	}
+}
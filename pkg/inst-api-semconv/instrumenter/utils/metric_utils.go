@@ -24,3 +24,18 @@ func NewFloat64Histogram(metricName, metricUnit, metricDescription string,
 	}
 	return d, fmt.Errorf("failed to create %s histogram, %w", metricName, err)
 }
+
+func NewInt64Histogram(metricName, metricUnit, metricDescription string,
+	meter metric.Meter,
+) (metric.Int64Histogram, error) {
+	if meter == nil {
+		return nil, errors.New("nil meter")
+	}
+	d, err := meter.Int64Histogram(metricName,
+		metric.WithUnit(metricUnit),
+		metric.WithDescription(metricDescription))
+	if err == nil {
+		return d, nil
+	}
+	return d, fmt.Errorf("failed to create %s histogram, %w", metricName, err)
+}
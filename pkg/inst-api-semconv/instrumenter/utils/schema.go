@@ -0,0 +1,11 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+// SchemaURL is the schema of the semantic conventions the extractors follow,
+// to set as the SchemaURL of the instrumentation scope of instrumenters using
+// them so that backends interpret their attributes accordingly
+const SchemaURL = semconv.SchemaURL
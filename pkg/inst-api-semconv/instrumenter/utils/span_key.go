@@ -10,4 +10,6 @@ const (
 	HTTPServerKey = attribute.Key("opentelemetry-traces-span-key-http-server")
 
 	ClientResendKey = attribute.Key("opentelemetry-http-client-resend-key")
+
+	CorrelationIDContextKey = attribute.Key("opentelemetry-correlation-id-key")
 )
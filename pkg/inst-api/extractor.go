@@ -5,10 +5,14 @@ package instrumenter
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
 )
 
 type AttributesExtractor[REQUEST any, RESPONSE any] interface {
@@ -18,6 +22,10 @@ type AttributesExtractor[REQUEST any, RESPONSE any] interface {
 		response RESPONSE, err error) ([]attribute.KeyValue, context.Context)
 }
 
+// AttributesProcessor rewrites the attributes gathered by all the extractors
+// before they are set on the span, e.g. to enforce an allowlist or rename keys.
+type AttributesProcessor func(attributes []attribute.KeyValue) []attribute.KeyValue
+
 type SpanKindExtractor[REQUEST any] interface {
 	Extract(request REQUEST) trace.SpanKind
 }
@@ -26,6 +34,45 @@ type SpanNameExtractor[REQUEST any] interface {
 	Extract(request REQUEST) string
 }
 
+// SpanNameExtractorFunc adapts an ordinary function to a SpanNameExtractor,
+// e.g. to name spans after a job type rather than the instrumented function.
+type SpanNameExtractorFunc[REQUEST any] func(request REQUEST) string
+
+func (f SpanNameExtractorFunc[REQUEST]) Extract(request REQUEST) string {
+	return f(request)
+}
+
+type spanNameOverrideKey struct{}
+
+// ContextWithSpanName overrides the name of the next span started from ctx,
+// taking precedence over the SpanNameExtractor of the instrumenter. The
+// override applies to that span only and is not inherited by its children.
+func ContextWithSpanName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, spanNameOverrideKey{}, name)
+}
+
+func spanNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(spanNameOverrideKey{}).(string)
+	return name
+}
+
+// RuleAttributeKey tags spans with the name of the instrumentation rule that
+// produced them when the rule attribute is enabled on the Builder.
+const RuleAttributeKey = inst.RuleKey
+
+type ruleNameKey struct{}
+
+// ContextWithRuleName records the name of the instrumentation rule, usually
+// taken from HookContext.GetRuleName, for the spans started from ctx.
+func ContextWithRuleName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, ruleNameKey{}, name)
+}
+
+func ruleNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(ruleNameKey{}).(string)
+	return name
+}
+
 type SpanStatusExtractor[REQUEST any, RESPONSE any] interface {
 	Extract(span trace.Span, request REQUEST, response RESPONSE, err error)
 }
@@ -64,6 +111,26 @@ func (_ *AlwaysConsumerExtractor[any]) Extract(_ any) trace.SpanKind {
 	return trace.SpanKindConsumer
 }
 
+// SpanKindExtractorFor returns the extractor that always yields the span kind
+// with the given name, one of internal, client, server, producer or consumer.
+// An empty name defaults to internal.
+func SpanKindExtractorFor[REQUEST any](kind string) (SpanKindExtractor[REQUEST], error) {
+	switch strings.ToLower(kind) {
+	case "", "internal":
+		return &AlwaysInternalExtractor[REQUEST]{}, nil
+	case "client":
+		return &AlwaysClientExtractor[REQUEST]{}, nil
+	case "server":
+		return &AlwaysServerExtractor[REQUEST]{}, nil
+	case "producer":
+		return &AlwaysProducerExtractor[REQUEST]{}, nil
+	case "consumer":
+		return &AlwaysConsumerExtractor[REQUEST]{}, nil
+	default:
+		return nil, fmt.Errorf("unknown span kind %q", kind)
+	}
+}
+
 type defaultSpanStatusExtractor[request any, response any] struct{}
 
 func (*defaultSpanStatusExtractor[REQUEST, RESPONSE]) Extract(
@@ -70,3 +70,26 @@ func TestAlwaysProducerExtractor(t *testing.T) {
 		t.Fatal("expected producer kind")
 	}
 }
+
+func TestSpanKindExtractorFor(t *testing.T) {
+	kinds := map[string]trace.SpanKind{
+		"":         trace.SpanKindInternal,
+		"internal": trace.SpanKindInternal,
+		"client":   trace.SpanKindClient,
+		"Server":   trace.SpanKindServer,
+		"producer": trace.SpanKindProducer,
+		"consumer": trace.SpanKindConsumer,
+	}
+	for name, expected := range kinds {
+		extractor, err := SpanKindExtractorFor[any](name)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", name, err)
+		}
+		if kind := extractor.Extract(nil); kind != expected {
+			t.Fatalf("expected %v for %q, got %v", expected, name, kind)
+		}
+	}
+	if _, err := SpanKindExtractorFor[any]("unknown"); err == nil {
+		t.Fatal("expected error for unknown kind")
+	}
+}
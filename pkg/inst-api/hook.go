@@ -5,9 +5,11 @@ package instrumenter
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type OperationListener interface {
@@ -17,6 +19,14 @@ type OperationListener interface {
 	OnAfterEnd(context context.Context, endAttributes []attribute.KeyValue, endTimestamp time.Time)
 }
 
+// MetricListener is implemented by the operation listeners recording metrics,
+// instrumenters built with MetricsDisabled do not register them. Other
+// listeners, e.g. SlowOperationListener, are always registered.
+type MetricListener interface {
+	OperationListener
+	RecordsMetrics()
+}
+
 type AttrsShadower interface {
 	Shadow(attrs []attribute.KeyValue) (int, []attribute.KeyValue)
 }
@@ -30,3 +40,65 @@ func (NoopAttrsShadower) Shadow(attrs []attribute.KeyValue) (int, []attribute.Ke
 type ContextCustomizer[REQUEST any] interface {
 	OnStart(context context.Context, request REQUEST, startAttributes []attribute.KeyValue) context.Context
 }
+
+type slowOperationStartKey struct{}
+
+type slowOperationStart struct {
+	timestamp  time.Time
+	attributes []attribute.KeyValue
+}
+
+// SlowOperationListener is an OperationListener that logs the operations
+// lasting longer than Threshold, together with their span name and attributes.
+type SlowOperationListener struct {
+	Threshold time.Duration
+	// Logger receives the slow operations, slog.Default() is used when nil
+	Logger *slog.Logger
+}
+
+func NewSlowOperationListener(threshold time.Duration) *SlowOperationListener {
+	return &SlowOperationListener{Threshold: threshold}
+}
+
+func (*SlowOperationListener) OnBeforeStart(parentContext context.Context, _ time.Time) context.Context {
+	return parentContext
+}
+
+func (*SlowOperationListener) OnBeforeEnd(
+	ctx context.Context,
+	startAttributes []attribute.KeyValue,
+	startTimestamp time.Time,
+) context.Context {
+	return context.WithValue(ctx, slowOperationStartKey{}, slowOperationStart{
+		timestamp:  startTimestamp,
+		attributes: startAttributes,
+	})
+}
+
+func (*SlowOperationListener) OnAfterStart(_ context.Context, _ time.Time) {}
+
+func (s *SlowOperationListener) OnAfterEnd(ctx context.Context, endAttributes []attribute.KeyValue, endTimestamp time.Time) {
+	start, ok := ctx.Value(slowOperationStartKey{}).(slowOperationStart)
+	if !ok {
+		return
+	}
+	duration := endTimestamp.Sub(start.timestamp)
+	if duration <= s.Threshold {
+		return
+	}
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	args := []any{"duration", duration, "threshold", s.Threshold}
+	// Only SDK spans expose their name
+	if span, named := trace.SpanFromContext(ctx).(interface{ Name() string }); named {
+		args = append(args, "span", span.Name())
+	}
+	for _, attrs := range [][]attribute.KeyValue{start.attributes, endAttributes} {
+		for _, attr := range attrs {
+			args = append(args, string(attr.Key), attr.Value.Emit())
+		}
+	}
+	logger.WarnContext(ctx, "slow operation", args...)
+}
@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumenter
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+)
+
+const (
+	hookInvocationsName  = "otel.instrumentation.hook.invocations"
+	hookInvocationsScope = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+// hookInvocations holds the counter of each global MeterProvider, so that
+// replacing the provider does not register the counter again
+var hookInvocations sync.Map
+
+// RecordHookInvocation counts a call of the hook of rule, usually taken from
+// HookContext.GetRuleName, in the otel.instrumentation.hook.invocations
+// counter. phase is inst.PhaseBefore or inst.PhaseAfter. Hooks call it first so
+// that rules that never fire stand out. The counter is only attributed by rule
+// and phase to keep its cardinality bounded by the number of rules.
+func RecordHookInvocation(rule, phase string) {
+	if compiledOut {
+		return
+	}
+	counter := hookInvocationsCounter()
+	if counter == nil {
+		return
+	}
+	counter.Add(context.Background(), 1, metric.WithAttributes(
+		inst.RuleKey.String(rule),
+		inst.PhaseKey.String(phase),
+	))
+}
+
+func hookInvocationsCounter() metric.Int64Counter {
+	mp := otel.GetMeterProvider()
+	if !reflect.TypeOf(mp).Comparable() {
+		return newHookInvocationsCounter(mp)
+	}
+	if counter, ok := hookInvocations.Load(mp); ok {
+		return counter.(metric.Int64Counter)
+	}
+	counter := newHookInvocationsCounter(mp)
+	if counter == nil {
+		return nil
+	}
+	actual, _ := hookInvocations.LoadOrStore(mp, counter)
+	return actual.(metric.Int64Counter)
+}
+
+func newHookInvocationsCounter(mp metric.MeterProvider) metric.Int64Counter {
+	counter, err := mp.Meter(hookInvocationsScope).Int64Counter(hookInvocationsName,
+		metric.WithUnit("{invocation}"),
+		metric.WithDescription("Number of calls of the hooks of each instrumentation rule."))
+	if err != nil {
+		return nil
+	}
+	return counter
+}
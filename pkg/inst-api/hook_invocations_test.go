@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumenter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+)
+
+func TestRecordHookInvocation(t *testing.T) {
+	originalMP := otel.GetMeterProvider()
+	defer otel.SetMeterProvider(originalMP)
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	for range 3 {
+		RecordHookInvocation("server_hook", inst.PhaseBefore)
+		RecordHookInvocation("server_hook", inst.PhaseAfter)
+	}
+	RecordHookInvocation("client_hook", inst.PhaseBefore)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Equal(t, hookInvocationsScope, rm.ScopeMetrics[0].Scope.Name)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	metric := rm.ScopeMetrics[0].Metrics[0]
+	require.Equal(t, hookInvocationsName, metric.Name)
+	sum, ok := metric.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	counts := make(map[[2]string]int64)
+	for _, dp := range sum.DataPoints {
+		rule, _ := dp.Attributes.Value(RuleAttributeKey)
+		phase, _ := dp.Attributes.Value(inst.PhaseKey)
+		require.Equal(t, 2, dp.Attributes.Len(), "only the rule and phase should be recorded")
+		counts[[2]string{rule.AsString(), phase.AsString()}] = dp.Value
+	}
+	require.Equal(t, map[[2]string]int64{
+		{"server_hook", "before"}: 3,
+		{"server_hook", "after"}:  3,
+		{"client_hook", "before"}: 1,
+	}, counts)
+}
+
+func TestRecordHookInvocationProviderChange(t *testing.T) {
+	originalMP := otel.GetMeterProvider()
+	defer otel.SetMeterProvider(originalMP)
+	for range 2 {
+		reader := sdkmetric.NewManualReader()
+		otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+		RecordHookInvocation("server_hook", inst.PhaseBefore)
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		require.Len(t, rm.ScopeMetrics, 1, "the counter should follow the global provider")
+		sum := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+		require.Len(t, sum.DataPoints, 1)
+		require.Equal(t, int64(1), sum.DataPoints[0].Value)
+		require.Equal(t, attribute.NewSet(RuleAttributeKey.String("server_hook"), inst.PhaseKey.String("before")),
+			sum.DataPoints[0].Attributes)
+	}
+}
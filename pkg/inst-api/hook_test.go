@@ -4,11 +4,17 @@
 package instrumenter
 
 import (
+	"bytes"
 	"context"
+	"log/slog"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 type testKey string
@@ -108,3 +114,120 @@ func TestOnAfterEnd(t *testing.T) {
 		t.Fatal("start attribute value is not equal to new start attribute value")
 	}
 }
+
+// orderListener records the callbacks it observes into a shared log
+type orderListener struct {
+	name string
+	log  *[]string
+}
+
+func (o *orderListener) OnBeforeStart(parentContext context.Context, _ time.Time) context.Context {
+	*o.log = append(*o.log, o.name+".OnBeforeStart")
+	return parentContext
+}
+
+func (o *orderListener) OnBeforeEnd(ctx context.Context, _ []attribute.KeyValue, _ time.Time) context.Context {
+	*o.log = append(*o.log, o.name+".OnBeforeEnd")
+	return ctx
+}
+
+func (o *orderListener) OnAfterStart(_ context.Context, _ time.Time) {
+	*o.log = append(*o.log, o.name+".OnAfterStart")
+}
+
+func (o *orderListener) OnAfterEnd(_ context.Context, _ []attribute.KeyValue, _ time.Time) {
+	*o.log = append(*o.log, o.name+".OnAfterEnd")
+}
+
+func TestOperationListenersOrder(t *testing.T) {
+	var calls []string
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysInternalExtractor[testRequest]{}).
+		AddOperationListeners(&orderListener{name: "first", log: &calls}).
+		AddOperationListeners(&orderListener{name: "second", log: &calls}, NewSlowOperationListener(time.Hour))
+	instrumenter := builder.BuildInstrumenter()
+	ctx := instrumenter.Start(context.Background(), testRequest{})
+	instrumenter.End(ctx, Invocation[testRequest, testResponse]{})
+
+	expected := []string{
+		"first.OnBeforeStart", "second.OnBeforeStart",
+		"first.OnBeforeEnd", "second.OnBeforeEnd",
+		"first.OnAfterStart", "second.OnAfterStart",
+		"first.OnAfterEnd", "second.OnAfterEnd",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, calls)
+		}
+	}
+}
+
+// metricOrderListener is an orderListener tagged as recording metrics
+type metricOrderListener struct {
+	orderListener
+}
+
+func (*metricOrderListener) RecordsMetrics() {}
+
+func TestOperationListenersMetricsDisabled(t *testing.T) {
+	var calls []string
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysInternalExtractor[testRequest]{}).
+		AddOperationListeners(
+			&orderListener{name: "trace", log: &calls},
+			&metricOrderListener{orderListener{name: "metric", log: &calls}},
+		).
+		SetMetricsDisabled(true)
+	instrumenter := builder.BuildInstrumenter()
+	ctx := instrumenter.Start(context.Background(), testRequest{})
+	instrumenter.End(ctx, Invocation[testRequest, testResponse]{})
+
+	expected := []string{
+		"trace.OnBeforeStart", "trace.OnBeforeEnd", "trace.OnAfterStart", "trace.OnAfterEnd",
+	}
+	if !slices.Equal(calls, expected) {
+		t.Fatalf("only the listener not recording metrics should be called, expected %v, got %v", expected, calls)
+	}
+}
+
+func TestSlowOperationListener(t *testing.T) {
+	var buf bytes.Buffer
+	listener := NewSlowOperationListener(time.Second)
+	listener.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysInternalExtractor[testRequest]{}).
+		AddAttributesExtractor(testAttributesExtractor{}).
+		AddOperationListeners(listener)
+	instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+
+	start := time.Now()
+	instrumenter.StartAndEnd(context.Background(), Invocation[testRequest, testResponse]{
+		StartTimeStamp: start,
+		EndTimeStamp:   start.Add(500 * time.Millisecond),
+	})
+	if buf.Len() != 0 {
+		t.Fatalf("fast operation should not be logged, got %q", buf.String())
+	}
+
+	instrumenter.StartAndEnd(context.Background(), Invocation[testRequest, testResponse]{
+		StartTimeStamp: start,
+		EndTimeStamp:   start.Add(2 * time.Second),
+	})
+	line := buf.String()
+	for _, expected := range []string{"slow operation", "duration=2s", "span=test", "testAttribute=testValue"} {
+		if !strings.Contains(line, expected) {
+			t.Fatalf("expected %q in log line %q", expected, line)
+		}
+	}
+}
@@ -5,8 +5,11 @@ package instrumenter
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -15,7 +18,8 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
-// Invocation encapsulates the parameters needed for ending instrumentation operations
+// Invocation encapsulates the parameters needed for ending instrumentation operations.
+// Zero timestamps are taken from the Clock of the instrumenter.
 type Invocation[REQUEST any, RESPONSE any] struct {
 	Request        REQUEST
 	Response       RESPONSE
@@ -75,7 +79,11 @@ type InternalInstrumenter[REQUEST any, RESPONSE any] struct {
 	contextCustomizers   []ContextCustomizer[REQUEST]
 	tracer               trace.Tracer
 	instVersion          string
-	attributesPool       *sync.Pool
+	maxAttrValueLength   int
+	maxSpansPerTrace     int
+	clock                Clock
+	ruleAttrEnabled      bool
+	attributesProcessor  AttributesProcessor
 }
 
 // PropagatingToDownstreamInstrumenter do instrumentation and propagate the context to downstream.
@@ -98,6 +106,76 @@ type PropagatingFromUpstreamInstrumenter[REQUEST any, RESPONSE any] struct {
 
 const defaultAttributesSliceSize = 25
 
+// attributesPool recycles the attribute slices of ended operations, it is
+// shared by the instrumenters which end operations concurrently
+var attributesPool = sync.Pool{
+	New: func() any {
+		s := make([]attribute.KeyValue, 0, defaultAttributesSliceSize)
+		return &s
+	},
+}
+
+const (
+	// DefaultMaxAttributeValueLength is the length in bytes beyond which string
+	// attribute values, e.g. url.full or db.statement, are truncated.
+	DefaultMaxAttributeValueLength = 2048
+	truncatedMarker                = "..."
+	truncatedSuffix                = ".truncated"
+)
+
+// truncateAttributes cuts string values longer than limit bytes, marking them
+// with an ellipsis and a companion <key>.truncated=true attribute. A limit of
+// zero or less disables truncation.
+func truncateAttributes(attrs []attribute.KeyValue, limit int) []attribute.KeyValue {
+	if limit <= 0 {
+		return attrs
+	}
+	for idx, n := 0, len(attrs); idx < n; idx++ {
+		if attrs[idx].Value.Type() != attribute.STRING {
+			continue
+		}
+		value := attrs[idx].Value.AsString()
+		if len(value) <= limit {
+			continue
+		}
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(value[cut]) {
+			cut--
+		}
+		attrs[idx].Value = attribute.StringValue(value[:cut] + truncatedMarker)
+		attrs = append(attrs, attribute.Bool(string(attrs[idx].Key)+truncatedSuffix, true))
+	}
+	return attrs
+}
+
+// dedupAttributes drops the repeated keys of attrs in place. Extractors run in
+// the order they were added, so the last one writing a key wins, and the key
+// keeps the position where it was first written.
+func dedupAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	n := 0
+next:
+	for _, attr := range attrs {
+		for idx := 0; idx < n; idx++ {
+			if attrs[idx].Key == attr.Key {
+				attrs[idx] = attr
+				continue next
+			}
+		}
+		attrs[n] = attr
+		n++
+	}
+	return attrs[:n]
+}
+
+// sortAttributes sorts attrs by key in place. The order of span attributes is
+// not meaningful, it is only stabilized so that exported spans compare equal
+// across runs, e.g. in golden tests.
+func sortAttributes(attrs []attribute.KeyValue) {
+	slices.SortStableFunc(attrs, func(a, b attribute.KeyValue) int {
+		return strings.Compare(string(a.Key), string(b.Key))
+	})
+}
+
 func (*InternalInstrumenter[REQUEST, RESPONSE]) ShouldStart(parentContext context.Context, request REQUEST) bool {
 	// TODO: Here you can add some custom logic to determine whether the instrumentation logic is executed or not.
 	_ = parentContext
@@ -128,7 +206,16 @@ func (i *InternalInstrumenter[REQUEST, RESPONSE]) Start(
 	request REQUEST,
 	options ...trace.SpanStartOption,
 ) context.Context {
-	return i.doStart(parentContext, request, time.Now(), options...)
+	return i.doStart(parentContext, request, i.now(), options...)
+}
+
+// now returns the current time of the clock, falling back to the system time
+// for instrumenters built without one
+func (i *InternalInstrumenter[REQUEST, RESPONSE]) now() time.Time {
+	if i.clock == nil {
+		return time.Now()
+	}
+	return i.clock.Now()
 }
 
 func (i *InternalInstrumenter[REQUEST, RESPONSE]) doStart(
@@ -137,23 +224,52 @@ func (i *InternalInstrumenter[REQUEST, RESPONSE]) doStart(
 	timestamp time.Time,
 	options ...trace.SpanStartOption,
 ) context.Context {
-	if i.enabler != nil && !i.enabler.Enable() {
+	if compiledOut || (i.enabler != nil && !i.enabler.Enable()) {
 		return parentContext
 	}
+	budget := spanBudgetFromContext(parentContext)
+	if !budget.take() {
+		return dropSpan(parentContext)
+	}
+	if timestamp.IsZero() {
+		timestamp = i.now()
+	}
 	for _, listener := range i.operationListeners {
 		//nolint:fatcontext // There will not be so many operation listeners here
 		parentContext = listener.OnBeforeStart(parentContext, timestamp)
 	}
 	// extract span name
-	spanName := i.spanNameExtractor.Extract(request)
+	spanName := spanNameFromContext(parentContext)
+	if spanName == "" {
+		spanName = i.spanNameExtractor.Extract(request)
+	} else {
+		// Don't leak the override to the children spans
+		parentContext = ContextWithSpanName(parentContext, "")
+	}
+	ruleName := ruleNameFromContext(parentContext)
+	if ruleName != "" {
+		// Same as the span name, the rule only describes this span
+		parentContext = ContextWithRuleName(parentContext, "")
+	}
 	spanKind := i.spanKindExtractor.Extract(request)
 	options = append(options, trace.WithSpanKind(spanKind), trace.WithTimestamp(timestamp))
 	newCtx, span := i.tracer.Start(parentContext, spanName, options...)
+	if budget == nil && i.maxSpansPerTrace > 0 {
+		newCtx = contextWithSpanBudget(newCtx, span, i.maxSpansPerTrace)
+	}
 	attrs := make([]attribute.KeyValue, 0, defaultAttributesSliceSize)
 	currentCtx := newCtx
 	for _, extractor := range i.attributesExtractors {
 		attrs, currentCtx = extractor.OnStart(currentCtx, attrs, request)
 	}
+	attrs = dedupAttributes(attrs)
+	if i.ruleAttrEnabled && ruleName != "" {
+		attrs = append(attrs, RuleAttributeKey.String(ruleName))
+	}
+	if i.attributesProcessor != nil {
+		attrs = i.attributesProcessor(attrs)
+	}
+	attrs = truncateAttributes(attrs, i.maxAttrValueLength)
 	for _, customizer := range i.contextCustomizers {
 		//nolint:fatcontext // There will not be so many customizers here
 		currentCtx = customizer.OnStart(currentCtx, request, attrs)
@@ -181,9 +297,15 @@ func (i *InternalInstrumenter[REQUEST, RESPONSE]) doEnd(
 	timestamp time.Time,
 	options ...trace.SpanEndOption,
 ) {
-	if i.enabler != nil && !i.enabler.Enable() {
+	if compiledOut || (i.enabler != nil && !i.enabler.Enable()) {
 		return
 	}
+	if dropped(ctx) {
+		return
+	}
+	if timestamp.IsZero() {
+		timestamp = i.now()
+	}
 	for _, listener := range i.operationListeners {
 		listener.OnAfterStart(ctx, timestamp)
 	}
@@ -192,17 +314,7 @@ func (i *InternalInstrumenter[REQUEST, RESPONSE]) doEnd(
 		span.RecordError(invocation.Err)
 		span.SetStatus(codes.Error, invocation.Err.Error())
 	}
-	// Initialize pool if not already initialized
-	if i.attributesPool == nil {
-		i.attributesPool = &sync.Pool{
-			New: func() any {
-				s := make([]attribute.KeyValue, 0, defaultAttributesSliceSize)
-				return &s
-			},
-		}
-	}
-
-	attrsPtr, _ := i.attributesPool.Get().(*[]attribute.KeyValue)
+	attrsPtr, _ := attributesPool.Get().(*[]attribute.KeyValue)
 	var attrs []attribute.KeyValue
 	if attrsPtr != nil {
 		attrs = *attrsPtr
@@ -211,12 +323,18 @@ func (i *InternalInstrumenter[REQUEST, RESPONSE]) doEnd(
 	}
 	defer func() {
 		attrs = attrs[:0]
-		i.attributesPool.Put(&attrs)
+		attributesPool.Put(&attrs)
 	}()
 	currentCtx := ctx
 	for _, extractor := range i.attributesExtractors {
 		attrs, currentCtx = extractor.OnEnd(currentCtx, attrs, invocation.Request, invocation.Response, invocation.Err)
 	}
+	attrs = dedupAttributes(attrs)
+	if i.attributesProcessor != nil {
+		attrs = i.attributesProcessor(attrs)
+	}
+	attrs = truncateAttributes(attrs, i.maxAttrValueLength)
+	sortAttributes(attrs)
 	i.spanStatusExtractor.Extract(span, invocation.Request, invocation.Response, invocation.Err)
 	span.SetAttributes(attrs...)
 	options = append(options, trace.WithTimestamp(timestamp))
@@ -239,6 +357,9 @@ func (p *PropagatingToDownstreamInstrumenter[REQUEST, RESPONSE]) StartAndEndWith
 	startOptions []trace.SpanStartOption,
 	endOptions []trace.SpanEndOption,
 ) {
+	if compiledOut {
+		return
+	}
 	newCtx := p.base.Start(parentContext, invocation.Request, startOptions...)
 	if p.carrierGetter != nil {
 		if p.prop != nil {
@@ -262,6 +383,9 @@ func (p *PropagatingToDownstreamInstrumenter[REQUEST, RESPONSE]) Start(
 	request REQUEST,
 	options ...trace.SpanStartOption,
 ) context.Context {
+	if compiledOut {
+		return parentContext
+	}
 	newCtx := p.base.Start(parentContext, request, options...)
 	if p.carrierGetter != nil {
 		if p.prop != nil {
@@ -294,6 +418,9 @@ func (p *PropagatingFromUpstreamInstrumenter[REQUEST, RESPONSE]) StartAndEndWith
 	startOptions []trace.SpanStartOption,
 	endOptions []trace.SpanEndOption,
 ) {
+	if compiledOut {
+		return
+	}
 	var ctx context.Context
 	if p.carrierGetter != nil {
 		var extracted context.Context
@@ -321,6 +448,9 @@ func (p *PropagatingFromUpstreamInstrumenter[REQUEST, RESPONSE]) Start(
 	request REQUEST,
 	options ...trace.SpanStartOption,
 ) context.Context {
+	if compiledOut {
+		return parentContext
+	}
 	if p.carrierGetter != nil {
 		var extracted context.Context
 		if p.prop != nil {
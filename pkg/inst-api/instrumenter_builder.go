@@ -4,6 +4,11 @@
 package instrumenter
 
 import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/instrumentation"
@@ -24,6 +29,71 @@ func (*defaultInstrumentEnabler) Enable() bool {
 	return true
 }
 
+// Clock provides the timestamps of the instrumented operations, it can be
+// replaced in tests to make durations deterministic
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func NewSystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type envInstrumentEnabler struct {
+	enabled bool
+}
+
+// NewEnvInstrumentEnabler returns an enabler reading the environment variable
+// OTEL_INSTRUMENTATION_<NAME>_ENABLED once, so that a compiled-in
+// instrumentation can be turned off without rebuilding. It is enabled unless
+// the variable is set to false, or all telemetry is disabled by
+// OTEL_SDK_DISABLED.
+func NewEnvInstrumentEnabler(name string) InstrumentEnabler {
+	enabled, err := strconv.ParseBool(os.Getenv(enabledEnvName(name)))
+	return &envInstrumentEnabler{enabled: (enabled || err != nil) && !SDKDisabled()}
+}
+
+// SDKDisabled reports whether OTEL_SDK_DISABLED is set to true, which turns off
+// all telemetry as in the OTel SDKs. Any other value is ignored.
+func SDKDisabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("OTEL_SDK_DISABLED")), "true")
+}
+
+// RuleEnabled reports whether the hooks of the named rule, usually taken from
+// HookContext.GetRuleName, should start telemetry. A rule is turned off by
+// setting OTEL_INSTRUMENTATION_<RULE>_ENABLED to false, e.g.
+// OTEL_INSTRUMENTATION_SERVER_HOOK_ENABLED=false for server_hook, while the
+// other rules of its package keep running. The package-level enabler still
+// applies to the enabled rules. The variable is read on every call, as for
+// OTEL_SDK_DISABLED.
+func RuleEnabled(rule string) bool {
+	if rule == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(os.Getenv(enabledEnvName(rule)))
+	return enabled || err != nil
+}
+
+func (e *envInstrumentEnabler) Enable() bool {
+	return e.enabled
+}
+
+func enabledEnvName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	return "OTEL_INSTRUMENTATION_" + strings.ToUpper(name) + "_ENABLED"
+}
+
 type Builder[REQUEST any, RESPONSE any] struct {
 	Enabler              InstrumentEnabler
 	SpanNameExtractor    SpanNameExtractor[REQUEST]
@@ -34,6 +104,20 @@ type Builder[REQUEST any, RESPONSE any] struct {
 	ContextCustomizers   []ContextCustomizer[REQUEST]
 	InstVersion          string
 	Scope                instrumentation.Scope
+	// MaxAttributeValueLength caps the length in bytes of string attribute
+	// values, zero or less disables truncation
+	MaxAttributeValueLength int
+	// MaxSpansPerTrace caps the number of spans started under the first span
+	// of a trace in the process, zero or less means unlimited
+	MaxSpansPerTrace int
+	// MetricsDisabled builds traces-only instrumenters, the operation listeners
+	// that record metrics, i.e. the MetricListener ones, are not registered
+	MetricsDisabled bool
+	Clock           Clock
+	// RuleAttributeEnabled tags spans with the originating rule name, meant for
+	// debugging only as it raises the attribute cardinality
+	RuleAttributeEnabled bool
+	AttributesProcessor  AttributesProcessor
 }
 
 func (b *Builder[REQUEST, RESPONSE]) Init() *Builder[REQUEST, RESPONSE] {
@@ -41,9 +125,13 @@ func (b *Builder[REQUEST, RESPONSE]) Init() *Builder[REQUEST, RESPONSE] {
 	b.AttributesExtractors = make([]AttributesExtractor[REQUEST, RESPONSE], 0)
 	b.ContextCustomizers = make([]ContextCustomizer[REQUEST], 0)
 	b.SpanStatusExtractor = &defaultSpanStatusExtractor[REQUEST, RESPONSE]{}
+	b.MaxAttributeValueLength = DefaultMaxAttributeValueLength
+	b.Clock = NewSystemClock()
 	return b
 }
 
+// SetInstrumentationScope sets the scope of the tracer, its SchemaURL should be
+// the one of the semantic conventions the extractors follow.
 func (b *Builder[REQUEST, RESPONSE]) SetInstrumentationScope(scope instrumentation.Scope) *Builder[REQUEST, RESPONSE] {
 	b.Scope = scope
 	return b
@@ -75,6 +163,41 @@ func (b *Builder[REQUEST, RESPONSE]) SetSpanKindExtractor(
 	return b
 }
 
+func (b *Builder[REQUEST, RESPONSE]) SetMaxAttributeValueLength(length int) *Builder[REQUEST, RESPONSE] {
+	b.MaxAttributeValueLength = length
+	return b
+}
+
+func (b *Builder[REQUEST, RESPONSE]) SetMaxSpansPerTrace(limit int) *Builder[REQUEST, RESPONSE] {
+	b.MaxSpansPerTrace = limit
+	return b
+}
+
+func (b *Builder[REQUEST, RESPONSE]) SetMetricsDisabled(disabled bool) *Builder[REQUEST, RESPONSE] {
+	b.MetricsDisabled = disabled
+	return b
+}
+
+func (b *Builder[REQUEST, RESPONSE]) SetClock(clock Clock) *Builder[REQUEST, RESPONSE] {
+	b.Clock = clock
+	return b
+}
+
+func (b *Builder[REQUEST, RESPONSE]) SetRuleAttributeEnabled(enabled bool) *Builder[REQUEST, RESPONSE] {
+	b.RuleAttributeEnabled = enabled
+	return b
+}
+
+func (b *Builder[REQUEST, RESPONSE]) SetAttributesProcessor(
+	attributesProcessor AttributesProcessor,
+) *Builder[REQUEST, RESPONSE] {
+	b.AttributesProcessor = attributesProcessor
+	return b
+}
+
+// AddAttributesExtractor appends extractors, which run in the order they were
+// added. When several extractors write the same key, the value of the last
+// one is recorded, once.
 func (b *Builder[REQUEST, RESPONSE]) AddAttributesExtractor(
 	attributesExtractor ...AttributesExtractor[REQUEST, RESPONSE],
 ) *Builder[REQUEST, RESPONSE] {
@@ -96,6 +219,19 @@ func (b *Builder[REQUEST, RESPONSE]) AddContextCustomizers(
 	return b
 }
 
+func (b *Builder[REQUEST, RESPONSE]) operationListeners() []OperationListener {
+	if !b.MetricsDisabled {
+		return b.OperationListeners
+	}
+	listeners := make([]OperationListener, 0, len(b.OperationListeners))
+	for _, listener := range b.OperationListeners {
+		if _, ok := listener.(MetricListener); !ok {
+			listeners = append(listeners, listener)
+		}
+	}
+	return listeners
+}
+
 func (b *Builder[REQUEST, RESPONSE]) BuildInstrumenter() *InternalInstrumenter[REQUEST, RESPONSE] {
 	tracer := otel.GetTracerProvider().
 		Tracer(b.Scope.Name,
@@ -107,10 +243,15 @@ func (b *Builder[REQUEST, RESPONSE]) BuildInstrumenter() *InternalInstrumenter[R
 		spanKindExtractor:    b.SpanKindExtractor,
 		spanStatusExtractor:  b.SpanStatusExtractor,
 		attributesExtractors: b.AttributesExtractors,
-		operationListeners:   b.OperationListeners,
+		operationListeners:   b.operationListeners(),
 		contextCustomizers:   b.ContextCustomizers,
 		tracer:               tracer,
 		instVersion:          b.InstVersion,
+		maxAttrValueLength:   b.MaxAttributeValueLength,
+		maxSpansPerTrace:     b.MaxSpansPerTrace,
+		clock:                b.Clock,
+		ruleAttrEnabled:      b.RuleAttributeEnabled,
+		attributesProcessor:  b.AttributesProcessor,
 	}
 }
 
@@ -123,10 +264,15 @@ func (b *Builder[REQUEST, RESPONSE]) BuildInstrumenterWithTracer(
 		spanKindExtractor:    b.SpanKindExtractor,
 		spanStatusExtractor:  b.SpanStatusExtractor,
 		attributesExtractors: b.AttributesExtractors,
-		operationListeners:   b.OperationListeners,
+		operationListeners:   b.operationListeners(),
 		contextCustomizers:   b.ContextCustomizers,
 		tracer:               tracer,
 		instVersion:          b.InstVersion,
+		maxAttrValueLength:   b.MaxAttributeValueLength,
+		maxSpansPerTrace:     b.MaxSpansPerTrace,
+		clock:                b.Clock,
+		ruleAttrEnabled:      b.RuleAttributeEnabled,
+		attributesProcessor:  b.AttributesProcessor,
 	}
 }
 
@@ -145,10 +291,15 @@ func (b *Builder[REQUEST, RESPONSE]) BuildPropagatingToDownstreamInstrumenter(
 			spanKindExtractor:    b.SpanKindExtractor,
 			spanStatusExtractor:  b.SpanStatusExtractor,
 			attributesExtractors: b.AttributesExtractors,
-			operationListeners:   b.OperationListeners,
+			operationListeners:   b.operationListeners(),
 			contextCustomizers:   b.ContextCustomizers,
 			tracer:               tracer,
 			instVersion:          b.InstVersion,
+			maxAttrValueLength:   b.MaxAttributeValueLength,
+			maxSpansPerTrace:     b.MaxSpansPerTrace,
+			clock:                b.Clock,
+			ruleAttrEnabled:      b.RuleAttributeEnabled,
+			attributesProcessor:  b.AttributesProcessor,
 		},
 		carrierGetter: carrierGetter,
 		prop:          prop,
@@ -170,9 +321,14 @@ func (b *Builder[REQUEST, RESPONSE]) BuildPropagatingFromUpstreamInstrumenter(
 			spanKindExtractor:    b.SpanKindExtractor,
 			spanStatusExtractor:  b.SpanStatusExtractor,
 			attributesExtractors: b.AttributesExtractors,
-			operationListeners:   b.OperationListeners,
+			operationListeners:   b.operationListeners(),
 			tracer:               tracer,
 			instVersion:          b.InstVersion,
+			maxAttrValueLength:   b.MaxAttributeValueLength,
+			maxSpansPerTrace:     b.MaxSpansPerTrace,
+			clock:                b.Clock,
+			ruleAttrEnabled:      b.RuleAttributeEnabled,
+			attributesProcessor:  b.AttributesProcessor,
 		},
 		carrierGetter: carrierGetter,
 		prop:          prop,
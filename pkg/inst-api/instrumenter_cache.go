@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumenter
+
+import (
+	"reflect"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+)
+
+// instrumenterCacheKey identifies the instrumenter of a type, e.g. the server
+// or the client one, of an instrumentation scope
+type instrumenterCacheKey struct {
+	scope        instrumentation.Scope
+	instrumenter reflect.Type
+}
+
+var (
+	instrumenterCacheMu sync.RWMutex
+	instrumenterCache   = make(map[instrumenterCacheKey]any)
+)
+
+// GetOrBuildInstrumenter returns the instrumenter of type T built by build for
+// scope, so that building it repeatedly does not register its metric
+// instruments again. It is built on first use and kept until
+// InvalidateInstrumenters is called for scope, e.g. once the settings read by
+// build change. It is cheap enough to be called for every request.
+func GetOrBuildInstrumenter[T any](scope instrumentation.Scope, build func() T) T {
+	key := instrumenterCacheKey{scope: scope, instrumenter: reflect.TypeFor[T]()}
+	instrumenterCacheMu.RLock()
+	cached, ok := instrumenterCache[key]
+	instrumenterCacheMu.RUnlock()
+	if ok {
+		return cached.(T)
+	}
+	instrumenterCacheMu.Lock()
+	defer instrumenterCacheMu.Unlock()
+	if cached, ok := instrumenterCache[key]; ok {
+		return cached.(T)
+	}
+	built := build()
+	instrumenterCache[key] = built
+	return built
+}
+
+// InvalidateInstrumenters drops the instrumenters cached for scope, they are
+// built again on their next use. Instrumenters are bound to the global
+// providers of their first use, which forward to the providers set later
+// with otel.SetTracerProvider and otel.SetMeterProvider. Replacing providers
+// that were already set, e.g. in tests, requires invalidating them.
+func InvalidateInstrumenters(scope instrumentation.Scope) {
+	instrumenterCacheMu.Lock()
+	defer instrumenterCacheMu.Unlock()
+	for key := range instrumenterCache {
+		if key.scope == scope {
+			delete(instrumenterCache, key)
+		}
+	}
+}
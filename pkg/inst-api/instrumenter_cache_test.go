@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumenter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestGetOrBuildInstrumenter(t *testing.T) {
+	originalMP := otel.GetMeterProvider()
+	defer otel.SetMeterProvider(originalMP)
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider())
+
+	scope := instrumentation.Scope{Name: "cache-test", Version: "0.0.1"}
+	defer InvalidateInstrumenters(scope)
+	registrations := 0
+	build := func() Instrumenter[testRequest, testResponse] {
+		registrations++
+		builder := Builder[testRequest, testResponse]{}
+		return builder.Init().
+			SetSpanNameExtractor(testNameExtractor{}).
+			SetSpanKindExtractor(&AlwaysClientExtractor[testRequest]{}).
+			BuildInstrumenter()
+	}
+	first := GetOrBuildInstrumenter(scope, build)
+	second := GetOrBuildInstrumenter(scope, build)
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, registrations, "the instrumenter should be built once")
+
+	// Another scope or instrumenter type is built separately
+	other := instrumentation.Scope{Name: "other"}
+	defer InvalidateInstrumenters(other)
+	GetOrBuildInstrumenter(other, build)
+	assert.Equal(t, 2, registrations)
+	GetOrBuildInstrumenter(scope, func() *InternalInstrumenter[testRequest, testResponse] {
+		registrations++
+		return nil
+	})
+	assert.Equal(t, 3, registrations)
+
+	// Replacing the provider alone keeps the cached instrumenter
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider())
+	assert.Same(t, first, GetOrBuildInstrumenter(scope, build))
+	assert.Equal(t, 3, registrations)
+
+	// Invalidated instrumenters of the scope are built again, the other
+	// scopes are kept
+	InvalidateInstrumenters(scope)
+	rebuilt := GetOrBuildInstrumenter(scope, build)
+	assert.NotSame(t, first, rebuilt)
+	assert.Equal(t, 4, registrations)
+	GetOrBuildInstrumenter(other, build)
+	assert.Equal(t, 4, registrations)
+}
@@ -6,10 +6,12 @@ package instrumenter
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
@@ -223,6 +225,61 @@ func TestEnabler(t *testing.T) {
 	}
 }
 
+func TestEnvInstrumentEnabler(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	for _, value := range []string{"false", "true", ""} {
+		t.Setenv("OTEL_INSTRUMENTATION_MY_DB_ENABLED", value)
+		builder := Builder[testRequest, testResponse]{}
+		builder.Init().
+			SetSpanNameExtractor(testNameExtractor{}).
+			SetSpanKindExtractor(&AlwaysClientExtractor[testRequest]{}).
+			SetInstrumentEnabler(NewEnvInstrumentEnabler("my-db"))
+		instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+		ctx := instrumenter.Start(context.Background(), testRequest{})
+		instrumenter.End(ctx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+	}
+	// Only the enabled runs record a span
+	assert.Len(t, sr.Ended(), 2)
+}
+
+func TestEnvInstrumentEnablerSDKDisabled(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	t.Setenv("OTEL_INSTRUMENTATION_MY_DB_ENABLED", "true")
+	for _, value := range []string{"true", "TRUE", "false", "1", ""} {
+		t.Setenv("OTEL_SDK_DISABLED", value)
+		builder := Builder[testRequest, testResponse]{}
+		builder.Init().
+			SetSpanNameExtractor(testNameExtractor{}).
+			SetSpanKindExtractor(&AlwaysClientExtractor[testRequest]{}).
+			SetInstrumentEnabler(NewEnvInstrumentEnabler("my-db"))
+		instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+		ctx := instrumenter.Start(context.Background(), testRequest{})
+		instrumenter.End(ctx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+	}
+	// Only "true", case insensitive, disables the SDK
+	assert.Len(t, sr.Ended(), 3)
+}
+
+func TestRuleEnabled(t *testing.T) {
+	for _, tt := range []struct {
+		value    string
+		expected bool
+	}{
+		{value: "false", expected: false},
+		{value: "0", expected: false},
+		{value: "true", expected: true},
+		{value: "", expected: true},
+		{value: "invalid", expected: true},
+	} {
+		t.Setenv("OTEL_INSTRUMENTATION_QUERY_HOOK_ENABLED", tt.value)
+		assert.Equal(t, tt.expected, RuleEnabled("query_hook"), tt.value)
+		assert.True(t, RuleEnabled("exec_hook"), "the other rules stay enabled")
+	}
+	assert.True(t, RuleEnabled(""), "hooks without a rule name are enabled")
+}
+
 func TestPropFromUpStream(t *testing.T) {
 	builder := Builder[testRequest, testResponse]{}
 	builder.Init().
@@ -401,3 +458,442 @@ func TestSpanTimestamps(t *testing.T) {
 	assert.Equal(t, startTime, recordedSpan.StartTime())
 	assert.Equal(t, endTime, recordedSpan.EndTime())
 }
+
+func TestCustomSpanName(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(SpanNameExtractorFunc[testRequest](func(testRequest) string {
+			return "job-type"
+		})).
+		SetSpanKindExtractor(&AlwaysInternalExtractor[testRequest]{})
+	instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+
+	ctx := instrumenter.Start(context.Background(), testRequest{})
+	instrumenter.End(ctx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+
+	// The context override takes precedence and only applies to one span
+	parent := instrumenter.Start(ContextWithSpanName(context.Background(), "overridden"), testRequest{})
+	child := instrumenter.Start(parent, testRequest{})
+	instrumenter.End(child, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+	instrumenter.End(parent, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	assert.Equal(t, "job-type", spans[0].Name())
+	assert.Equal(t, "job-type", spans[1].Name())
+	assert.Equal(t, "overridden", spans[2].Name())
+}
+
+func TestSpanKindFromRule(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	kindExtractor, err := SpanKindExtractorFor[testRequest]("client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(kindExtractor)
+	instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+
+	ctx := instrumenter.Start(context.Background(), testRequest{})
+	instrumenter.End(ctx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+}
+
+type longURLAttributesExtractor struct {
+	url string
+}
+
+func (l longURLAttributesExtractor) OnStart(
+	parentContext context.Context,
+	attributes []attribute.KeyValue,
+	_ testRequest,
+) ([]attribute.KeyValue, context.Context) {
+	return append(attributes, attribute.String("url.full", l.url)), parentContext
+}
+
+func (longURLAttributesExtractor) OnEnd(
+	ctx context.Context,
+	attributes []attribute.KeyValue,
+	_ testRequest,
+	_ testResponse,
+	_ error,
+) ([]attribute.KeyValue, context.Context) {
+	return attributes, ctx
+}
+
+func TestMaxAttributeValueLength(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	longURL := "http://example.com/?q=" + strings.Repeat("a", DefaultMaxAttributeValueLength)
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysClientExtractor[testRequest]{}).
+		AddAttributesExtractor(longURLAttributesExtractor{url: longURL})
+	instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+	ctx := instrumenter.Start(context.Background(), testRequest{})
+	instrumenter.End(ctx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+
+	builder.SetMaxAttributeValueLength(10)
+	instrumenter = builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+	ctx = instrumenter.Start(context.Background(), testRequest{})
+	instrumenter.End(ctx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	expected := []attribute.KeyValue{
+		attribute.String("url.full", longURL[:DefaultMaxAttributeValueLength]+"..."),
+		attribute.Bool("url.full.truncated", true),
+	}
+	assert.Equal(t, expected, spans[0].Attributes())
+	expected = []attribute.KeyValue{
+		attribute.String("url.full", "http://exa..."),
+		attribute.Bool("url.full.truncated", true),
+	}
+	assert.Equal(t, expected, spans[1].Attributes())
+
+	short := []attribute.KeyValue{attribute.String("url.full", "http://a")}
+	assert.Equal(t, short, truncateAttributes(short, 10))
+	assert.Equal(t, short, truncateAttributes(short, 0))
+}
+
+// keyAttributesExtractor writes a key with a value on both start and end
+type keyAttributesExtractor struct {
+	key   string
+	value string
+}
+
+func (k keyAttributesExtractor) OnStart(
+	parentContext context.Context,
+	attributes []attribute.KeyValue,
+	_ testRequest,
+) ([]attribute.KeyValue, context.Context) {
+	return append(attributes, attribute.String(k.key, k.value)), parentContext
+}
+
+func (k keyAttributesExtractor) OnEnd(
+	ctx context.Context,
+	attributes []attribute.KeyValue,
+	_ testRequest,
+	_ testResponse,
+	_ error,
+) ([]attribute.KeyValue, context.Context) {
+	return append(attributes, attribute.String(k.key+".end", k.value)), ctx
+}
+
+func TestAttributesExtractorsSameKey(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysClientExtractor[testRequest]{}).
+		AddAttributesExtractor(
+			keyAttributesExtractor{key: "shared", value: "first"},
+			keyAttributesExtractor{key: "other", value: "value"},
+			keyAttributesExtractor{key: "shared", value: "last"},
+		)
+	instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+	ctx := instrumenter.Start(context.Background(), testRequest{})
+	instrumenter.End(ctx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	// The last writer wins at the position of the first one, the end
+	// attributes are sorted by key
+	expected := []attribute.KeyValue{
+		attribute.String("shared", "last"),
+		attribute.String("other", "value"),
+		attribute.String("other.end", "value"),
+		attribute.String("shared.end", "last"),
+	}
+	assert.Equal(t, expected, spans[0].Attributes())
+}
+
+func TestEndAttributesOrder(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysClientExtractor[testRequest]{}).
+		AddAttributesExtractor(
+			keyAttributesExtractor{key: "c", value: "1"},
+			keyAttributesExtractor{key: "a", value: "1"},
+			keyAttributesExtractor{key: "b", value: "1"},
+		)
+	instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+	for range 3 {
+		ctx := instrumenter.Start(context.Background(), testRequest{})
+		instrumenter.End(ctx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+	}
+
+	spans := sr.Ended()
+	require.Len(t, spans, 3)
+	expected := []attribute.KeyValue{
+		attribute.String("c", "1"),
+		attribute.String("a", "1"),
+		attribute.String("b", "1"),
+		attribute.String("a.end", "1"),
+		attribute.String("b.end", "1"),
+		attribute.String("c.end", "1"),
+	}
+	for _, span := range spans {
+		assert.Equal(t, expected, span.Attributes())
+	}
+}
+
+func TestDedupAttributes(t *testing.T) {
+	attrs := []attribute.KeyValue{
+		attribute.String("a", "1"),
+		attribute.String("b", "1"),
+		attribute.String("a", "2"),
+		attribute.String("a", "3"),
+		attribute.String("c", "1"),
+	}
+	expected := []attribute.KeyValue{
+		attribute.String("a", "3"),
+		attribute.String("b", "1"),
+		attribute.String("c", "1"),
+	}
+	assert.Equal(t, expected, dedupAttributes(attrs))
+	assert.Empty(t, dedupAttributes(nil))
+}
+
+func TestNestedInternalSpans(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	originalTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(originalTP)
+
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysInternalExtractor[testRequest]{})
+	instrumenter := builder.BuildInstrumenter()
+
+	parent := instrumenter.Start(context.Background(), testRequest{})
+	child := instrumenter.Start(parent, testRequest{})
+	grandchild := instrumenter.Start(child, testRequest{})
+	instrumenter.End(grandchild, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+	instrumenter.End(child, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+	instrumenter.End(parent, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	grandchildSpan, childSpan, parentSpan := spans[0], spans[1], spans[2]
+	assert.False(t, parentSpan.Parent().IsValid())
+	assert.Equal(t, parentSpan.SpanContext().SpanID(), childSpan.Parent().SpanID())
+	assert.Equal(t, childSpan.SpanContext().SpanID(), grandchildSpan.Parent().SpanID())
+	assert.Equal(t, parentSpan.SpanContext().TraceID(), grandchildSpan.SpanContext().TraceID())
+}
+
+func TestTraceStateRoundTrip(t *testing.T) {
+	const (
+		traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		traceState  = "vendor1=opaque1,vendor2=opaque2"
+	)
+	sr := tracetest.NewSpanRecorder()
+	originalTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	defer otel.SetTracerProvider(originalTP)
+
+	incoming := propagation.MapCarrier{"traceparent": traceParent, "tracestate": traceState}
+	outgoing := propagation.MapCarrier{}
+	serverBuilder := Builder[testRequest, testResponse]{}
+	serverBuilder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysServerExtractor[testRequest]{})
+	server := serverBuilder.BuildPropagatingFromUpstreamInstrumenter(
+		func(testRequest) propagation.TextMapCarrier { return incoming },
+		propagation.TraceContext{},
+	)
+	clientBuilder := Builder[testRequest, testResponse]{}
+	clientBuilder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysClientExtractor[testRequest]{})
+	client := clientBuilder.BuildPropagatingToDownstreamInstrumenter(
+		func(testRequest) propagation.TextMapCarrier { return outgoing },
+		propagation.TraceContext{},
+	)
+
+	// An outbound call made within the handler
+	serverCtx := server.Start(context.Background(), testRequest{})
+	clientCtx := client.Start(serverCtx, testRequest{})
+	client.End(clientCtx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+	server.End(serverCtx, Invocation[testRequest, testResponse]{EndTimeStamp: time.Now()})
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		assert.Equal(t, traceState, span.SpanContext().TraceState().String())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	}
+	assert.Equal(t, traceState, outgoing.Get("tracestate"))
+	assert.Contains(t, outgoing.Get("traceparent"), "4bf92f3577b34da6a3ce929d0e0e4736")
+}
+
+func TestRuleAttribute(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		sr := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+		builder := Builder[testRequest, testResponse]{}
+		builder.Init().
+			SetSpanNameExtractor(testNameExtractor{}).
+			SetSpanKindExtractor(&AlwaysInternalExtractor[testRequest]{}).
+			SetRuleAttributeEnabled(enabled)
+		instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+
+		ctx := instrumenter.Start(ContextWithRuleName(context.Background(), "redis_process"), testRequest{})
+		childCtx := instrumenter.Start(ctx, testRequest{})
+		instrumenter.End(childCtx, Invocation[testRequest, testResponse]{})
+		instrumenter.End(ctx, Invocation[testRequest, testResponse]{})
+
+		spans := sr.Ended()
+		if len(spans) != 2 {
+			t.Fatalf("expected 2 spans, got %d", len(spans))
+		}
+		child, parent := spans[0], spans[1]
+		var rule attribute.Value
+		for _, attr := range parent.Attributes() {
+			if attr.Key == RuleAttributeKey {
+				rule = attr.Value
+			}
+		}
+		if enabled {
+			assert.Equal(t, "redis_process", rule.AsString())
+		} else {
+			assert.Equal(t, attribute.INVALID, rule.Type())
+		}
+		for _, attr := range child.Attributes() {
+			assert.NotEqual(t, RuleAttributeKey, attr.Key, "rule name leaked to child span")
+		}
+	}
+}
+
+func TestAttributesProcessor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysInternalExtractor[testRequest]{}).
+		AddAttributesExtractor(testAttributesExtractor{}, longURLAttributesExtractor{url: "http://example.com"}).
+		SetAttributesProcessor(func(attrs []attribute.KeyValue) []attribute.KeyValue {
+			processed := attrs[:0]
+			for _, attr := range attrs {
+				switch attr.Key {
+				case "url.full":
+					continue
+				case "testAttribute":
+					attr.Key = "renamedAttribute"
+				}
+				processed = append(processed, attr)
+			}
+			return processed
+		})
+	instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+	ctx := instrumenter.Start(context.Background(), testRequest{})
+	instrumenter.End(ctx, Invocation[testRequest, testResponse]{})
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range spans[0].Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	if _, ok := attrs["url.full"]; ok {
+		t.Fatal("url.full should be dropped")
+	}
+	if _, ok := attrs["testAttribute"]; ok {
+		t.Fatal("testAttribute should be renamed")
+	}
+	assert.Equal(t, "testValue", attrs["renamedAttribute"].AsString())
+}
+
+func TestMaxSpansPerTrace(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysInternalExtractor[testRequest]{}).
+		SetMaxSpansPerTrace(10)
+	instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+
+	// A runaway recursion, each call nested under the previous one
+	var recurse func(ctx context.Context, depth int)
+	recurse = func(ctx context.Context, depth int) {
+		if depth == 0 {
+			return
+		}
+		ctx = instrumenter.Start(ctx, testRequest{})
+		recurse(ctx, depth-1)
+		instrumenter.End(ctx, Invocation[testRequest, testResponse]{})
+	}
+	root := instrumenter.Start(context.Background(), testRequest{})
+	recurse(root, 100)
+	traceID := trace.SpanContextFromContext(root).TraceID()
+	instrumenter.End(root, Invocation[testRequest, testResponse]{})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 10, "the spans past the limit should be dropped")
+	for _, span := range spans {
+		require.Equal(t, traceID, span.SpanContext().TraceID())
+	}
+	rootSpan := spans[len(spans)-1]
+	require.False(t, rootSpan.Parent().IsValid())
+	require.Contains(t, rootSpan.Attributes(), TraceTruncatedKey.Bool(true))
+	for _, span := range spans[:len(spans)-1] {
+		require.NotContains(t, span.Attributes(), TraceTruncatedKey.Bool(true))
+	}
+
+	// Dropped operations still carry the trace of their parent
+	ended := len(sr.Ended())
+	root = instrumenter.Start(context.Background(), testRequest{})
+	ctx := root
+	for range 20 {
+		ctx = instrumenter.Start(ctx, testRequest{})
+	}
+	require.Equal(t, trace.SpanContextFromContext(root).TraceID(), trace.SpanContextFromContext(ctx).TraceID())
+	instrumenter.End(ctx, Invocation[testRequest, testResponse]{})
+	require.Len(t, sr.Ended(), ended, "ending a dropped span should not end its parent")
+}
+
+func TestMaxSpansPerTraceUnlimited(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	builder := Builder[testRequest, testResponse]{}
+	builder.Init().
+		SetSpanNameExtractor(testNameExtractor{}).
+		SetSpanKindExtractor(&AlwaysInternalExtractor[testRequest]{})
+	instrumenter := builder.BuildInstrumenterWithTracer(tp.Tracer("test-tracer"))
+
+	ctx := context.Background()
+	for range 100 {
+		ctx = instrumenter.Start(ctx, testRequest{})
+		instrumenter.End(ctx, Invocation[testRequest, testResponse]{})
+	}
+	require.Len(t, sr.Ended(), 100)
+}
@@ -0,0 +1,10 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build otelnoop
+
+package instrumenter
+
+// compiledOut turns every instrumenter into a no-op when building with the
+// otelnoop tag, for builds where telemetry must be absent
+const compiledOut = true
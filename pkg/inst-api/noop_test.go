@@ -0,0 +1,34 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumenter
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiledOut(t *testing.T) {
+	for _, tt := range []struct {
+		tags   string
+		output string
+	}{
+		{tags: "", output: "spans: 1\n"},
+		{tags: "otelnoop", output: "spans: 0\n"},
+	} {
+		bin := filepath.Join(t.TempDir(), "noop")
+		output, err := exec.Command("go", "build", "-tags", tt.tags, "-o", bin, "./testdata/noop").CombinedOutput()
+		require.NoError(t, err, string(output))
+		output, err = exec.Command(bin).CombinedOutput()
+		require.NoError(t, err, string(output))
+		assert.Equal(t, tt.output, string(output), "tags %q", tt.tags)
+
+		symbols, err := exec.Command("go", "tool", "nm", bin).CombinedOutput()
+		require.NoError(t, err, string(symbols))
+		assert.NotContains(t, string(symbols), "go.opentelemetry.io/otel/sdk/trace")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumenter
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceTruncatedKey flags the root span of a trace whose spans were dropped
+// past the MaxSpansPerTrace of the Builder
+const TraceTruncatedKey = attribute.Key("trace.truncated")
+
+type spanBudgetKey struct{}
+
+// spanBudget counts the spans started under a root span, it is shared by all
+// of its descendants whichever instrumenter starts them
+type spanBudget struct {
+	root      trace.Span
+	limit     int64
+	started   atomic.Int64
+	truncated atomic.Bool
+}
+
+// contextWithSpanBudget makes root, already started, the root of at most
+// limit spans started from ctx and its descendants
+func contextWithSpanBudget(ctx context.Context, root trace.Span, limit int) context.Context {
+	budget := &spanBudget{root: root, limit: int64(limit)}
+	budget.started.Store(1)
+	return context.WithValue(ctx, spanBudgetKey{}, budget)
+}
+
+func spanBudgetFromContext(ctx context.Context) *spanBudget {
+	budget, _ := ctx.Value(spanBudgetKey{}).(*spanBudget)
+	return budget
+}
+
+// take reports whether one more span can be started, the root is flagged the
+// first time the budget is exhausted. There is no limit without a budget.
+func (b *spanBudget) take() bool {
+	if b == nil || b.started.Add(1) <= b.limit {
+		return true
+	}
+	if b.truncated.CompareAndSwap(false, true) {
+		b.root.SetAttributes(TraceTruncatedKey.Bool(true))
+	}
+	return false
+}
+
+// droppedSpan stands in for the spans not started past the budget. It does
+// not record anything but carries the span context of its parent, so that the
+// operation still propagates the trace.
+type droppedSpan struct {
+	trace.Span
+}
+
+func dropSpan(ctx context.Context) context.Context {
+	parent := trace.SpanFromContext(trace.ContextWithSpanContext(ctx, trace.SpanContextFromContext(ctx)))
+	return trace.ContextWithSpan(ctx, &droppedSpan{Span: parent})
+}
+
+// dropped reports whether the span of ctx was dropped, its end is skipped
+func dropped(ctx context.Context) bool {
+	_, ok := trace.SpanFromContext(ctx).(*droppedSpan)
+	return ok
+}
@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// This program counts the spans started by an instrumenter, it is built with
+// and without the otelnoop tag by TestCompiledOut.
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+var started int
+
+type countingTracer struct {
+	noop.Tracer
+}
+
+func (t countingTracer) Start(ctx context.Context, name string,
+	options ...trace.SpanStartOption,
+) (context.Context, trace.Span) {
+	started++
+	return t.Tracer.Start(ctx, name, options...)
+}
+
+type nameExtractor struct{}
+
+func (nameExtractor) Extract(string) string {
+	return "operation"
+}
+
+func main() {
+	builder := &instrumenter.Builder[string, string]{}
+	inst := builder.Init().
+		SetSpanNameExtractor(nameExtractor{}).
+		SetSpanKindExtractor(&instrumenter.AlwaysInternalExtractor[string]{}).
+		BuildInstrumenterWithTracer(countingTracer{})
+	ctx := inst.Start(context.Background(), "request")
+	inst.End(ctx, instrumenter.Invocation[string, string]{Request: "request"})
+	fmt.Printf("spans: %d\n", started)
+}
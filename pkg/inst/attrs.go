@@ -0,0 +1,21 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package inst
+
+import "go.opentelemetry.io/otel/attribute"
+
+// RuleKey and PhaseKey attribute the telemetry about the instrumentation
+// itself, e.g. its panic and hook invocation counters, with the rule and the
+// phase of the hook concerned
+const (
+	RuleKey  = attribute.Key("otel.instrumentation.rule")
+	PhaseKey = attribute.Key("otel.instrumentation.phase")
+)
+
+// The phases of the hooks of a rule around their target function, the
+// trampolines report panics with the same values
+const (
+	PhaseBefore = "before"
+	PhaseAfter  = "after"
+)
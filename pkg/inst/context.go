@@ -4,6 +4,12 @@
 package inst
 
 // !!! pkg/inst/context.go will auto-sync to tool/internal/instrument/api.tmpl
+
+// HookContext is the first argument of every hook and takes no index itself.
+// Parameters are indexed in declaration order from 0, the receiver of a method
+// being parameter 0 and its first argument parameter 1. A variadic parameter
+// is a single slice. Return values are indexed in declaration order from 0 and
+// are only available to After hooks.
 type HookContext interface {
 	// Set the skip call flag, can be used to skip the original function call
 	SetSkipCall(bool)
@@ -13,6 +19,12 @@ type HookContext interface {
 	SetData(interface{})
 	// Get the data field, can be used to pass information between Before and After hooks
 	GetData() interface{}
+	// Set the value of key, can be used to pass several values between Before and After hooks
+	SetKeyData(key string, val interface{})
+	// Get the value of key, or nil if it was never set
+	GetKeyData(key string) interface{}
+	// Report whether a value was set for key
+	HasKeyData(key string) bool
 	// Number of original function parameters
 	GetParamCount() int
 	// Get the original function parameter at index idx
@@ -29,4 +41,9 @@ type HookContext interface {
 	GetFuncName() string
 	// Get the package name of the original function
 	GetPackageName() string
+	// Get the name of the rule that instrumented the original function
+	GetRuleName() string
+	// Get the first context.Context parameter of the original function wherever
+	// it is placed, or nil if there is none. Assert it to context.Context
+	Context() interface{}
 }
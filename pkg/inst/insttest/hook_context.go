@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package insttest provides utilities for testing hooks without building an
+// instrumented program.
+package insttest
+
+import (
+	"context"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+)
+
+// HookContext is an inst.HookContext for calling hooks directly in tests, as
+// the trampolines of an instrumented function would. Params and ReturnVals
+// hold the parameters and return values of the instrumented function, see
+// inst.HookContext for their indices.
+type HookContext struct {
+	Params      []interface{}
+	ReturnVals  []interface{}
+	FuncName    string
+	PackageName string
+	RuleName    string
+	// Ctx is returned by Context, i.e. the context.Context parameter
+	Ctx context.Context
+
+	skipCall bool
+	data     interface{}
+	keyData  map[string]interface{}
+}
+
+var _ inst.HookContext = (*HookContext)(nil)
+
+func (c *HookContext) SetSkipCall(skip bool)               { c.skipCall = skip }
+func (c *HookContext) IsSkipCall() bool                    { return c.skipCall }
+func (c *HookContext) SetData(data interface{})            { c.data = data }
+func (c *HookContext) GetData() interface{}                { return c.data }
+func (c *HookContext) GetKeyData(key string) interface{}   { return c.keyData[key] }
+func (c *HookContext) GetParamCount() int                  { return len(c.Params) }
+func (c *HookContext) GetParam(idx int) interface{}        { return c.Params[idx] }
+func (c *HookContext) SetParam(idx int, v interface{})     { c.Params[idx] = v }
+func (c *HookContext) GetReturnValCount() int              { return len(c.ReturnVals) }
+func (c *HookContext) GetReturnVal(idx int) interface{}    { return c.ReturnVals[idx] }
+func (c *HookContext) SetReturnVal(idx int, v interface{}) { c.ReturnVals[idx] = v }
+func (c *HookContext) GetFuncName() string                 { return c.FuncName }
+func (c *HookContext) GetPackageName() string              { return c.PackageName }
+func (c *HookContext) GetRuleName() string                 { return c.RuleName }
+
+func (c *HookContext) SetKeyData(key string, val interface{}) {
+	if c.keyData == nil {
+		c.keyData = make(map[string]interface{})
+	}
+	c.keyData[key] = val
+}
+
+func (c *HookContext) HasKeyData(key string) bool {
+	_, ok := c.keyData[key]
+	return ok
+}
+
+func (c *HookContext) Context() interface{} {
+	if c.Ctx == nil {
+		return nil
+	}
+	return c.Ctx
+}
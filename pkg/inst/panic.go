@@ -0,0 +1,36 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package inst
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	panicMeterName   = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+	panicCounterName = "otel.instrumentation.panics"
+)
+
+// RecordHookPanic increments the otel.instrumentation.panics counter, it is
+// called by the trampolines whenever a panic of a hook of the rule named name
+// is recovered in the given phase, i.e. PhaseBefore or PhaseAfter.
+// Panics are rare, so the counter is looked up on every call rather than
+// cached, which keeps it bound to the current global MeterProvider.
+func RecordHookPanic(name, phase string) {
+	counter, err := otel.GetMeterProvider().Meter(panicMeterName).Int64Counter(
+		panicCounterName,
+		metric.WithDescription("Number of panics recovered from instrumentation hooks"),
+		metric.WithUnit("{panic}"),
+	)
+	if err != nil {
+		return
+	}
+	counter.Add(context.Background(), 1, metric.WithAttributes(
+		RuleKey.String(name),
+		PhaseKey.String(phase),
+	))
+}
@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package inst
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRecordHookPanic(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	originalMP := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(originalMP)
+
+	RecordHookPanic("nethttp", PhaseBefore)
+	RecordHookPanic("nethttp", PhaseBefore)
+	RecordHookPanic("nethttp", PhaseAfter)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	m := rm.ScopeMetrics[0].Metrics[0]
+	require.Equal(t, panicCounterName, m.Name)
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.True(t, sum.IsMonotonic)
+
+	counts := make(map[string]int64)
+	for _, dp := range sum.DataPoints {
+		name, _ := dp.Attributes.Value(RuleKey)
+		require.Equal(t, "nethttp", name.AsString())
+		phase, _ := dp.Attributes.Value(PhaseKey)
+		counts[phase.AsString()] = dp.Value
+	}
+	require.Equal(t, map[string]int64{PhaseBefore: 2, PhaseAfter: 1}, counts)
+}
@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package databasesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+var databaseSQLInstrumenter = BuildDatabaseSQLInstrumenter()
+
+// databaseSQLData is passed from the before hook to the after hook
+type databaseSQLData struct {
+	ctx     context.Context
+	request DatabaseSQLRequest
+}
+
+func BeforeQueryContext(ictx inst.HookContext, db *sql.DB, ctx context.Context, query string, args ...interface{}) {
+	beforeStatement(ictx, db, ctx, query)
+}
+
+func AfterQueryContext(ictx inst.HookContext, rows *sql.Rows, err error) {
+	afterStatement(ictx, err)
+}
+
+func BeforeExecContext(ictx inst.HookContext, db *sql.DB, ctx context.Context, query string, args ...interface{}) {
+	beforeStatement(ictx, db, ctx, query)
+}
+
+func AfterExecContext(ictx inst.HookContext, result sql.Result, err error) {
+	afterStatement(ictx, err)
+}
+
+func beforeStatement(ictx inst.HookContext, db *sql.DB, ctx context.Context, query string) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseBefore)
+	if !instrumenter.RuleEnabled(ictx.GetRuleName()) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	request := DatabaseSQLRequest{
+		System:    dbSystem(db),
+		Statement: query,
+	}
+	newCtx := databaseSQLInstrumenter.Start(instrumenter.ContextWithRuleName(ctx, ictx.GetRuleName()), request)
+	// Propagate the span context to the driver
+	ictx.SetParam(1, newCtx)
+	ictx.SetData(&databaseSQLData{ctx: newCtx, request: request})
+}
+
+func afterStatement(ictx inst.HookContext, err error) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseAfter)
+	data, ok := ictx.GetData().(*databaseSQLData)
+	if !ok || data == nil {
+		return
+	}
+	databaseSQLInstrumenter.End(data.ctx, instrumenter.Invocation[DatabaseSQLRequest, DatabaseSQLResponse]{
+		Request:  data.request,
+		Response: DatabaseSQLResponse{},
+		Err:      err,
+	})
+}
+
+// dbSystem guesses the db.system value from the type of the driver
+func dbSystem(db *sql.DB) string {
+	if db == nil || db.Driver() == nil {
+		return semconv.DBSystemOtherSQL.Value.AsString()
+	}
+	driverType := strings.ToLower(fmt.Sprintf("%T", db.Driver()))
+	switch {
+	case strings.Contains(driverType, "mysql"):
+		return semconv.DBSystemMySQL.Value.AsString()
+	case strings.Contains(driverType, "pq."), strings.Contains(driverType, "pgx"),
+		strings.Contains(driverType, "postgres"):
+		return semconv.DBSystemPostgreSQL.Value.AsString()
+	case strings.Contains(driverType, "sqlite"):
+		return semconv.DBSystemSqlite.Value.AsString()
+	case strings.Contains(driverType, "mssql"), strings.Contains(driverType, "sqlserver"):
+		return semconv.DBSystemMSSQL.Value.AsString()
+	default:
+		return semconv.DBSystemOtherSQL.Value.AsString()
+	}
+}
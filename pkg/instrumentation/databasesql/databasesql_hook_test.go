@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package databasesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst/insttest"
+)
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestQueryContextHooks(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	originalTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(originalTP)
+
+	sql.Register("fakedb", fakeDriver{})
+	db, err := sql.Open("fakedb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := "SELECT name FROM users WHERE id = 42"
+	ictx := &insttest.HookContext{Params: make([]interface{}, 4)}
+	BeforeQueryContext(ictx, db, context.Background(), query, 42)
+	newCtx, ok := ictx.GetParam(1).(context.Context)
+	require.True(t, ok)
+	assert.True(t, trace.SpanContextFromContext(newCtx).IsValid())
+	queryErr := errors.New("query failed")
+	AfterQueryContext(ictx, nil, queryErr)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "SELECT", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	attrs := attribute.NewSet(span.Attributes()...)
+	system, _ := attrs.Value(semconv.DBSystemKey)
+	assert.Equal(t, semconv.DBSystemOtherSQL.Value.AsString(), system.AsString())
+	statement, _ := attrs.Value(semconv.DBStatementKey)
+	assert.Equal(t, "SELECT name FROM users WHERE id = ?", statement.AsString())
+	operation, _ := attrs.Value(semconv.DBOperationKey)
+	assert.Equal(t, "SELECT", operation.AsString())
+}
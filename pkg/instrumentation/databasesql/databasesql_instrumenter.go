@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package databasesql
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+const instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/instrumentation/databasesql"
+
+// DatabaseSQLRequest describes a single statement issued through database/sql.
+type DatabaseSQLRequest struct {
+	System    string
+	Statement string
+}
+
+type DatabaseSQLResponse struct{}
+
+// StatementSanitizer rewrites a raw statement before it is recorded as the
+// db.statement attribute, typically to strip sensitive literals.
+type StatementSanitizer func(statement string) string
+
+// Sanitizer is used to sanitize every recorded statement. It defaults to
+// SanitizeStatement and can be replaced to customize or disable sanitizing.
+var Sanitizer StatementSanitizer = SanitizeStatement
+
+type databaseSQLSpanNameExtractor struct{}
+
+func (d databaseSQLSpanNameExtractor) Extract(request DatabaseSQLRequest) string {
+	if op := operationName(request.Statement); op != "" {
+		return op
+	}
+	if request.System != "" {
+		return request.System
+	}
+	return "DB"
+}
+
+type databaseSQLAttrsExtractor struct{}
+
+func (d databaseSQLAttrsExtractor) OnStart(parentContext context.Context, attributes []attribute.KeyValue,
+	request DatabaseSQLRequest,
+) ([]attribute.KeyValue, context.Context) {
+	statement := request.Statement
+	if Sanitizer != nil {
+		statement = Sanitizer(statement)
+	}
+	attributes = append(attributes,
+		semconv.DBSystemKey.String(request.System),
+		semconv.DBStatement(statement),
+	)
+	if op := operationName(request.Statement); op != "" {
+		attributes = append(attributes, semconv.DBOperation(op))
+	}
+	return attributes, parentContext
+}
+
+func (d databaseSQLAttrsExtractor) OnEnd(context context.Context, attributes []attribute.KeyValue,
+	request DatabaseSQLRequest, response DatabaseSQLResponse, err error,
+) ([]attribute.KeyValue, context.Context) {
+	return attributes, context
+}
+
+// operationName returns the upper-cased leading keyword of the statement,
+// e.g. SELECT or INSERT.
+func operationName(statement string) string {
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+func BuildDatabaseSQLInstrumenter() instrumenter.Instrumenter[DatabaseSQLRequest, DatabaseSQLResponse] {
+	builder := &instrumenter.Builder[DatabaseSQLRequest, DatabaseSQLResponse]{}
+	return builder.Init().SetSpanNameExtractor(databaseSQLSpanNameExtractor{}).
+		SetSpanKindExtractor(&instrumenter.AlwaysClientExtractor[DatabaseSQLRequest]{}).
+		AddAttributesExtractor(databaseSQLAttrsExtractor{}).
+		SetInstrumentEnabler(instrumenter.NewEnvInstrumentEnabler("databasesql")).
+		SetInstrumentationScope(instrumentation.Scope{
+			Name:      instrumentationName,
+			Version:   "0.0.1",
+			SchemaURL: semconv.SchemaURL,
+		}).BuildInstrumenter()
+}
@@ -0,0 +1,16 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package databasesql
+
+import (
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/db"
+)
+
+var defaultSanitizer = db.NewStatementSanitizer()
+
+// SanitizeStatement replaces string and numeric literals in the statement
+// with a "?" placeholder, leaving keywords and identifiers untouched.
+func SanitizeStatement(statement string) string {
+	return defaultSanitizer.Sanitize(statement)
+}
@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package databasesql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		expected  string
+	}{
+		{
+			name:      "select with parameters",
+			statement: "SELECT * FROM users WHERE id = 42 AND name = 'bob' AND score > 3.5",
+			expected:  "SELECT * FROM users WHERE id = ? AND name = ? AND score > ?",
+		},
+		{
+			name:      "escaped quote",
+			statement: "SELECT * FROM users WHERE name = 'o''brien'",
+			expected:  "SELECT * FROM users WHERE name = ?",
+		},
+		{
+			name:      "digits in identifiers are kept",
+			statement: "SELECT col1 FROM table2 WHERE x = 7",
+			expected:  "SELECT col1 FROM table2 WHERE x = ?",
+		},
+		{
+			name:      "placeholders are kept",
+			statement: "INSERT INTO users (id, name) VALUES (?, $1)",
+			expected:  "INSERT INTO users (id, name) VALUES (?, $1)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SanitizeStatement(tt.statement))
+		})
+	}
+}
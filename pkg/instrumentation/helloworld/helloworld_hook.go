@@ -11,8 +11,10 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
 	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
@@ -24,6 +26,12 @@ func init() {
 
 func setupOpenTelemetry() {
 	fmt.Println("=setupOpenTelemetry=")
+	if instrumenter.SDKDisabled() {
+		// All telemetry is turned off, skip the exporters
+		otel.SetTracerProvider(tracenoop.NewTracerProvider())
+		otel.SetMeterProvider(metricnoop.NewMeterProvider())
+		return
+	}
 	// Print all the signal to stdout
 	spanExporter, _ := stdouttrace.New()
 	stdoutTraceProvider := trace.NewTracerProvider(trace.WithSpanProcessor(trace.NewSimpleSpanProcessor(spanExporter)))
@@ -43,7 +51,11 @@ func MyHookBefore(ictx inst.HookContext) {
 	ctx := context.Background()
 	// We should assign the returned context to ctx variable to make sure the context to be propagated properly
 	fmt.Println("[MyHook] start to instrument hello world!")
-	ctx = helloWorldInstrumenter.Start(ctx, HelloWorldRequest{})
+	request := HelloWorldRequest{
+		FuncName:    ictx.GetFuncName(),
+		PackageName: ictx.GetPackageName(),
+	}
+	ctx = helloWorldInstrumenter.Start(ctx, request)
 	// biz logic
 	// .........
 	// .........
@@ -51,7 +63,7 @@ func MyHookBefore(ictx inst.HookContext) {
 	time.Sleep(2 * time.Second)
 	// We should use instrumenter#end to end the span and to aggregate the metrics
 	helloWorldInstrumenter.End(ctx, instrumenter.Invocation[HelloWorldRequest, HelloWorldResponse]{
-		Request:  HelloWorldRequest{},
+		Request:  request,
 		Response: HelloWorldResponse{},
 	})
 	fmt.Println("[MyHook] hello world is instrumented!")
@@ -77,3 +89,7 @@ func MyHook1After(ictx inst.HookContext) {
 }
 
 func BeforeUnderscore(ictx inst.HookContext, _ int, _ float32) {}
+
+func BeforeVariadic(ictx inst.HookContext, prefix string, items ...int) {
+	fmt.Printf("variadic:%s %v\n", prefix, items)
+}
@@ -10,11 +10,18 @@ import (
 	"go.opentelemetry.io/otel/sdk/instrumentation"
 
 	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/code"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/http"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/net"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/utils"
 )
 
-type HelloWorldRequest struct{}
+// HelloWorldRequest identifies the instrumented function, as reported by the
+// HookContext
+type HelloWorldRequest struct {
+	FuncName    string
+	PackageName string
+}
 
 type HelloWorldResponse struct{}
 
@@ -26,6 +33,14 @@ func (h helloWorldSpanNameExtractor) Extract(request HelloWorldRequest) string {
 	return "hello-world"
 }
 
+func (h HelloWorldAttributesGetter) GetCodeFunction(request HelloWorldRequest) string {
+	return request.FuncName
+}
+
+func (h HelloWorldAttributesGetter) GetCodeNamespace(request HelloWorldRequest) string {
+	return request.PackageName
+}
+
 func (h HelloWorldAttributesGetter) GetURLScheme(request HelloWorldRequest) string {
 	return "http"
 }
@@ -44,15 +59,19 @@ func BuildNetHttpClientOtelInstrumenter() instrumenter.Instrumenter[HelloWorldRe
 	urlAttributesExtractor := &net.URLAttrsExtractor[HelloWorldRequest, HelloWorldResponse, HelloWorldAttributesGetter]{
 		Getter: helloWorldGetter,
 	}
+	codeAttributesExtractor := &code.CodeAttrsExtractor[HelloWorldRequest, HelloWorldResponse, HelloWorldAttributesGetter]{
+		Getter: helloWorldGetter,
+	}
 	clientMetricRegistry := http.NewMetricsRegistry(slog.Default(), otel.GetMeterProvider().Meter("hello-world"))
 	// TODO: return noop instrumenter when there is an error
 	clientMetrics, _ := clientMetricRegistry.NewHTTPClientMetric("hello.world.client")
 	return builder.Init().SetSpanNameExtractor(helloWorldSpanNameExtractor{}).
 		SetSpanKindExtractor(&instrumenter.AlwaysInternalExtractor[HelloWorldRequest]{}).
-		AddAttributesExtractor(urlAttributesExtractor).
+		AddAttributesExtractor(urlAttributesExtractor, codeAttributesExtractor).
 		AddOperationListeners(clientMetrics).
 		SetInstrumentationScope(instrumentation.Scope{
-			Name:    "hello-world",
-			Version: "0.0.1",
+			Name:      "hello-world",
+			Version:   "0.0.1",
+			SchemaURL: utils.SchemaURL,
 		}).BuildInstrumenter()
 }
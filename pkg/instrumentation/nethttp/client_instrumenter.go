@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+	semconvhttp "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/http"
+	semconvnet "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/net"
+)
+
+// HTTPClientRequest is the outgoing request sent by the instrumented
+// transport.
+type HTTPClientRequest struct {
+	Request *http.Request
+}
+
+// HTTPClientResponse is the response received by the instrumented transport,
+// Response is nil when the round trip failed.
+type HTTPClientResponse struct {
+	Response *http.Response
+}
+
+type clientAttrsGetter struct{}
+
+func (clientAttrsGetter) GetRequestMethod(request HTTPClientRequest) string {
+	return request.Request.Method
+}
+
+func (clientAttrsGetter) GetHTTPRequestHeader(request HTTPClientRequest, name string) []string {
+	return request.Request.Header.Values(name)
+}
+
+// GetServerAddress returns the host of the URL, requests without URL are
+// rejected by the transport but must not crash the instrumentation first
+func (clientAttrsGetter) GetServerAddress(request HTTPClientRequest) string {
+	if request.Request.URL == nil {
+		return ""
+	}
+	return request.Request.URL.Hostname()
+}
+
+// GetServerPort returns the port of the URL, or the default one of its
+// scheme
+func (clientAttrsGetter) GetServerPort(request HTTPClientRequest) int {
+	if request.Request.URL == nil {
+		return 0
+	}
+	if port, err := strconv.Atoi(request.Request.URL.Port()); err == nil {
+		return port
+	}
+	switch request.Request.URL.Scheme {
+	case "http":
+		return 80
+	case "https":
+		return 443
+	}
+	return 0
+}
+
+func (clientAttrsGetter) GetHTTPResponseStatusCode(_ HTTPClientRequest, response HTTPClientResponse, _ error) int {
+	if response.Response == nil {
+		return 0
+	}
+	return response.Response.StatusCode
+}
+
+func (clientAttrsGetter) GetHTTPResponseHeader(_ HTTPClientRequest, response HTTPClientResponse, name string) []string {
+	if response.Response == nil {
+		return nil
+	}
+	return response.Response.Header.Values(name)
+}
+
+func (clientAttrsGetter) GetHTTPRequestBodySize(request HTTPClientRequest) int64 {
+	return request.Request.ContentLength
+}
+
+// GetHTTPResponseBodySize returns the Content-Length of the response, which is
+// the compressed size when the body is still encoded. It is unknown when the
+// transport decoded the body, as it drops the Content-Length then.
+func (clientAttrsGetter) GetHTTPResponseBodySize(_ HTTPClientRequest, response HTTPClientResponse) int64 {
+	if response.Response == nil || response.Response.Uncompressed {
+		return -1
+	}
+	return response.Response.ContentLength
+}
+
+// GetHTTPResponseUncompressedBodySize returns the Content-Length of a response
+// decoded by the transport. http.Transport decodes gzip bodies as they are
+// read and reports -1, other transports may know the decoded size upfront.
+func (clientAttrsGetter) GetHTTPResponseUncompressedBodySize(_ HTTPClientRequest, response HTTPClientResponse) int64 {
+	if response.Response == nil || !response.Response.Uncompressed {
+		return -1
+	}
+	return response.Response.ContentLength
+}
+
+func (clientAttrsGetter) GetErrorType(_ HTTPClientRequest, response HTTPClientResponse, err error) string {
+	if err != nil {
+		return errorType(err)
+	}
+	if response.Response != nil && isErrorStatusCode(response.Response.StatusCode) {
+		return strconv.Itoa(response.Response.StatusCode)
+	}
+	return ""
+}
+
+// GetTLSNegotiatedProtocol returns the protocol negotiated through ALPN, e.g.
+// "h2", or "" for plaintext responses and when no protocol was negotiated.
+func (clientAttrsGetter) GetTLSNegotiatedProtocol(response HTTPClientResponse) string {
+	if response.Response == nil || response.Response.TLS == nil {
+		return ""
+	}
+	return response.Response.TLS.NegotiatedProtocol
+}
+
+// clientTLSAttrsExtractor records the ALPN protocol of the response as
+// tls.next_protocol, which tells HTTP/2 negotiation failures apart
+type clientTLSAttrsExtractor struct {
+	getter clientAttrsGetter
+}
+
+func (clientTLSAttrsExtractor) OnStart(parentContext context.Context, attributes []attribute.KeyValue,
+	_ HTTPClientRequest,
+) ([]attribute.KeyValue, context.Context) {
+	return attributes, parentContext
+}
+
+func (e clientTLSAttrsExtractor) OnEnd(ctx context.Context, attributes []attribute.KeyValue,
+	_ HTTPClientRequest, response HTTPClientResponse, _ error,
+) ([]attribute.KeyValue, context.Context) {
+	if protocol := e.getter.GetTLSNegotiatedProtocol(response); protocol != "" {
+		attributes = append(attributes, semconv.TLSNextProtocol(protocol))
+	}
+	return attributes, ctx
+}
+
+// BuildClientInstrumenter builds the instrumenter of outgoing requests, the
+// span context is injected into the request headers.
+// Repeated calls return the same instrumenter until the settings are changed
+// with Configure.
+func BuildClientInstrumenter() *instrumenter.PropagatingToDownstreamInstrumenter[HTTPClientRequest, HTTPClientResponse] {
+	return instrumenter.GetOrBuildInstrumenter(instrumentationScope, buildClientInstrumenter)
+}
+
+func buildClientInstrumenter() *instrumenter.PropagatingToDownstreamInstrumenter[HTTPClientRequest, HTTPClientResponse] {
+	s := currentSettings()
+	getter := clientAttrsGetter{}
+	serverExtractor := semconvnet.CreateServerAttributesExtractor[HTTPClientRequest, HTTPClientResponse](getter)
+	builder := &instrumenter.Builder[HTTPClientRequest, HTTPClientResponse]{}
+	builder.Init()
+	registry := semconvhttp.NewMetricsRegistry(getLogger(), meter())
+	registry.SetAttributeKeys(s.MetricAttributeKeys...)
+	if clientMetrics, err := registry.NewHTTPClientMetric(instrumentationName); err == nil {
+		builder.AddOperationListeners(clientMetrics)
+	}
+	return builder.
+		SetSpanNameExtractor(&semconvhttp.HTTPClientSpanNameExtractor[HTTPClientRequest, HTTPClientResponse]{
+			Getter:            getter,
+			WithServerAddress: s.ClientSpanNameWithServerAddress,
+		}).
+		SetSpanKindExtractor(&instrumenter.AlwaysClientExtractor[HTTPClientRequest]{}).
+		SetSpanStatusExtractor(semconvhttp.HTTPClientSpanStatusExtractor[HTTPClientRequest, HTTPClientResponse]{
+			Getter:       getter,
+			StatusMapper: s.SpanStatusMapper,
+		}).
+		AddAttributesExtractor(&semconvhttp.HTTPClientAttrsExtractor[
+			HTTPClientRequest, HTTPClientResponse, clientAttrsGetter,
+		]{
+			Base: semconvhttp.HTTPCommonAttrsExtractor[HTTPClientRequest, HTTPClientResponse, clientAttrsGetter]{
+				HTTPGetter:   getter,
+				KnownMethods: s.KnownMethods,
+			},
+			CapturedResponseHeaders: s.CapturedResponseHeaders,
+		}, &serverExtractor, clientTLSAttrsExtractor{getter: getter}).
+		SetInstrumentEnabler(instrumenter.NewEnvInstrumentEnabler("nethttp")).
+		SetInstrumentationScope(instrumentationScope).
+		BuildPropagatingToDownstreamInstrumenter(func(request HTTPClientRequest) propagation.TextMapCarrier {
+			// The request is a clone, a missing header map can be set
+			if request.Request.Header == nil {
+				request.Request.Header = make(http.Header)
+			}
+			return propagation.HeaderCarrier(request.Request.Header)
+		}, otel.GetTextMapPropagator())
+}
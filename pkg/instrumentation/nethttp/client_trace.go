@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	clientDNSDuration     = "http.client.dns_duration"
+	clientConnectDuration = "http.client.connect_duration"
+	clientTLSDuration     = "http.client.tls_duration"
+	// durationKey is the duration of the connection phase in seconds
+	durationKey = attribute.Key("duration")
+)
+
+// ContextWithClientTrace installs an httptrace.ClientTrace that records the
+// DNS, connect and TLS phases of a client request as events of the span in
+// ctx. Phases only happen for fresh connections, so reused connections record
+// nothing. It is opt-in due to its overhead.
+func ContextWithClientTrace(ctx context.Context) context.Context {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return ctx
+	}
+	var dnsStart, connectStart, tlsStart time.Time
+	record := func(name string, start time.Time) {
+		if start.IsZero() {
+			return
+		}
+		end := time.Now()
+		span.AddEvent(name,
+			trace.WithTimestamp(end),
+			trace.WithAttributes(durationKey.Float64(end.Sub(start).Seconds())))
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { record(clientDNSDuration, dnsStart) },
+		ConnectStart: func(_, _ string) {
+			// Dialing may race several addresses, time the first attempt
+			if connectStart.IsZero() {
+				connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				record(clientConnectDuration, connectStart)
+			}
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				record(clientTLSDuration, tlsStart)
+			}
+		},
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestContextWithClientTrace(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	// Resolve localhost rather than dialing the IP so the DNS phase happens
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "GET")
+	req, err := http.NewRequestWithContext(ContextWithClientTrace(ctx), http.MethodGet, url, nil)
+	require.NoError(t, err)
+	client := server.Client()
+	// The test certificate is issued for example.com rather than localhost
+	client.Transport.(*http.Transport).TLSClientConfig.ServerName = "example.com"
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	span.End()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	events := make(map[string]bool)
+	for _, event := range spans[0].Events() {
+		require.Len(t, event.Attributes, 1)
+		require.Equal(t, durationKey, event.Attributes[0].Key)
+		events[event.Name] = true
+	}
+	require.True(t, events[clientDNSDuration], "missing DNS event")
+	require.True(t, events[clientConnectDuration], "missing connect event")
+	require.True(t, events[clientTLSDuration], "missing TLS event")
+}
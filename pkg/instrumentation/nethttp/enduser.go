@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// enduser.id was deprecated before the semconv version in use, so there is no
+// constant for it
+const endUserIDKey = attribute.Key("enduser.id")
+
+type endUserAttrsExtractor struct {
+	key  any
+	hash bool
+}
+
+func (endUserAttrsExtractor) OnStart(parentContext context.Context, attributes []attribute.KeyValue,
+	_ HTTPServerRequest,
+) ([]attribute.KeyValue, context.Context) {
+	return attributes, parentContext
+}
+
+// OnEnd reads the user ID once the handler returned, as it may be stored in a
+// holder that the authentication middleware fills during the request
+func (e endUserAttrsExtractor) OnEnd(ctx context.Context, attributes []attribute.KeyValue,
+	request HTTPServerRequest, _ HTTPServerResponse, _ error,
+) ([]attribute.KeyValue, context.Context) {
+	var id string
+	switch v := request.Request.Context().Value(e.key).(type) {
+	case string:
+		id = v
+	case fmt.Stringer:
+		id = v.String()
+	}
+	if id == "" {
+		return attributes, ctx
+	}
+	if e.hash {
+		sum := sha256.Sum256([]byte(id))
+		id = hex.EncodeToString(sum[:])
+	}
+	return append(attributes, endUserIDKey.String(id)), ctx
+}
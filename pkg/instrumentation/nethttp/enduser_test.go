@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type userIDKey struct{}
+
+func TestServerEndUserID(t *testing.T) {
+	tests := []struct {
+		name string
+		key  any
+		hash bool
+		id   string
+	}{
+		{name: "disabled"},
+		{name: "plain", key: userIDKey{}, id: "alice"},
+		{
+			name: "hashed",
+			key:  userIDKey{},
+			hash: true,
+			// sha256("alice")
+			id: "2bd806c97f0e00af1a1fc3328fa763a9269723c8db8fac4f93af71db186d6e90",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := useTracerProvider(t)
+			configure(t, func(s *Settings) { s.EndUserContextKey, s.HashEndUserID = tt.key, tt.hash })
+
+			handler := NewHandlerMiddleware()(http.NotFoundHandler())
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(context.WithValue(r.Context(), userIDKey{}, "alice"))
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			attrs := spanAttrs(spans[0])
+			id, ok := attrs[endUserIDKey]
+			if tt.id == "" {
+				require.False(t, ok)
+				return
+			}
+			require.Equal(t, tt.id, id.AsString())
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/utils"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/otelsetup"
+)
+
+const (
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/instrumentation/nethttp"
+	serverErrorSpanName = "http.server.error"
+)
+
+var instrumentationScope = instrumentation.Scope{
+	Name:      instrumentationName,
+	Version:   "0.0.1",
+	SchemaURL: utils.SchemaURL,
+}
+
+// tracer and meter share the scope of the instrumenters, for the telemetry
+// recorded outside of them
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationScope.Name,
+		trace.WithInstrumentationVersion(instrumentationScope.Version),
+		trace.WithSchemaURL(instrumentationScope.SchemaURL))
+}
+
+// getLogger returns the logger set with otelsetup.SetLogger
+func getLogger() *slog.Logger {
+	return otelsetup.Logger()
+}
+
+func meter() metric.Meter {
+	return otel.GetMeterProvider().Meter(instrumentationScope.Name,
+		metric.WithInstrumentationVersion(instrumentationScope.Version),
+		metric.WithSchemaURL(instrumentationScope.SchemaURL))
+}
+
+// errorLogWriter records every line of the server error log as a standalone
+// span, making connection-level errors visible next to the request spans
+type errorLogWriter struct{}
+
+func (errorLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+	_, span := tracer().Start(context.Background(), serverErrorSpanName)
+	span.RecordError(errors.New(msg))
+	span.SetStatus(codes.Error, msg)
+	span.End()
+	return len(p), nil
+}
+
+// NewErrorLog returns a logger to opt in as http.Server.ErrorLog so that
+// connection-level errors, e.g. TLS handshake failures, are recorded as spans.
+func NewErrorLog() *log.Logger {
+	return log.New(errorLogWriter{}, "", 0)
+}
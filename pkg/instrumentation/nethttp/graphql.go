@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+const (
+	graphQLOperationNameKey = attribute.Key("graphql.operation.name")
+	graphQLOperationTypeKey = attribute.Key("graphql.operation.type")
+	// maxGraphQLBodySize bounds the body read to find the operation, larger
+	// requests are not parsed
+	maxGraphQLBodySize = 64 << 10
+)
+
+// graphQLOperation is the operation of a GraphQL request, its name is empty
+// for anonymous operations
+type graphQLOperation struct {
+	name string
+	typ  string
+}
+
+// readGraphQLOperation returns the operation of r when it is a GraphQL
+// request to one of paths, or nil. The body is restored for the handler.
+func readGraphQLOperation(r *http.Request, paths []string) *graphQLOperation {
+	if r.Method != http.MethodPost || r.Body == nil || !slices.Contains(paths, r.URL.Path) {
+		return nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxGraphQLBodySize+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || len(body) > maxGraphQLBodySize {
+		return nil
+	}
+	var params struct {
+		Query         string `json:"query"`
+		OperationName string `json:"operationName"`
+	}
+	if json.Unmarshal(body, &params) != nil || params.Query == "" {
+		return nil
+	}
+	operations := parseGraphQLOperations(params.Query)
+	if params.OperationName == "" {
+		// The operation to execute is only implied when it is the only one
+		if len(operations) != 1 {
+			return nil
+		}
+		return &operations[0]
+	}
+	for _, op := range operations {
+		if op.name == params.OperationName {
+			return &op
+		}
+	}
+	return nil
+}
+
+// parseGraphQLOperations lists the operations defined at the top level of a
+// GraphQL document, fragments are skipped
+func parseGraphQLOperations(query string) []graphQLOperation {
+	var operations []graphQLOperation
+	depth := 0
+	// definition is set within a top-level definition, an opening brace
+	// outside of one starts an anonymous query in shorthand form
+	definition := false
+	// expectName is set after an operation type, the next name at the top
+	// level is the name of the operation
+	expectName := false
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '#':
+			// Comments run to the end of the line
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], `"""`):
+			end := strings.Index(query[i+3:], `"""`)
+			if end < 0 {
+				return operations
+			}
+			i += end + 6
+		case c == '"':
+			i++
+			for i < len(query) && query[i] != '"' {
+				if query[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case c == '{' || c == '(' || c == '[':
+			if c == '{' && depth == 0 && !definition {
+				operations = append(operations, graphQLOperation{typ: "query"})
+			}
+			definition = true
+			expectName = false
+			depth++
+			i++
+		case c == '}' || c == ')' || c == ']':
+			depth--
+			if c == '}' && depth == 0 {
+				definition = false
+			}
+			i++
+		case c == '@':
+			// Directives are not operation names
+			expectName = false
+			i++
+			for i < len(query) && (query[i] == '_' || isLetter(query[i]) || isDigit(query[i])) {
+				i++
+			}
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(query) && (query[i] == '_' || isLetter(query[i]) || isDigit(query[i])) {
+				i++
+			}
+			if depth > 0 {
+				continue
+			}
+			name := query[start:i]
+			definition = true
+			switch {
+			case expectName:
+				operations[len(operations)-1].name = name
+				expectName = false
+			case name == "query" || name == "mutation" || name == "subscription":
+				operations = append(operations, graphQLOperation{typ: name})
+				expectName = true
+			}
+		default:
+			i++
+		}
+	}
+	return operations
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// graphQLSpanNameExtractor names the spans of GraphQL requests after their
+// operation, e.g. "query GetUser", and falls back to base for other requests
+type graphQLSpanNameExtractor struct {
+	base instrumenter.SpanNameExtractor[HTTPServerRequest]
+}
+
+func (e graphQLSpanNameExtractor) Extract(request HTTPServerRequest) string {
+	op := request.graphQL
+	if op == nil {
+		return e.base.Extract(request)
+	}
+	if op.name == "" {
+		return op.typ
+	}
+	return op.typ + " " + op.name
+}
+
+type graphQLAttrsExtractor struct{}
+
+func (graphQLAttrsExtractor) OnStart(parentContext context.Context, attributes []attribute.KeyValue,
+	request HTTPServerRequest,
+) ([]attribute.KeyValue, context.Context) {
+	op := request.graphQL
+	if op == nil {
+		return attributes, parentContext
+	}
+	attributes = append(attributes, graphQLOperationTypeKey.String(op.typ))
+	if op.name != "" {
+		attributes = append(attributes, graphQLOperationNameKey.String(op.name))
+	}
+	return attributes, parentContext
+}
+
+func (graphQLAttrsExtractor) OnEnd(ctx context.Context, attributes []attribute.KeyValue,
+	_ HTTPServerRequest, _ HTTPServerResponse, _ error,
+) ([]attribute.KeyValue, context.Context) {
+	return attributes, ctx
+}
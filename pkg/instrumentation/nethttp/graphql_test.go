@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerGraphQLOperation(t *testing.T) {
+	configure(t, func(s *Settings) { s.GraphQLPaths = []string{"/graphql"} })
+	sr := useTracerProvider(t)
+
+	const body = `{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","operationName":"GetUser","variables":{"id":"42"}}`
+	var received string
+	handler := NewHandlerMiddleware()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = string(b)
+	}))
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	require.Equal(t, body, received, "the handler should read the whole body")
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "query GetUser", spans[0].Name())
+	attrs := spanAttrs(spans[0])
+	require.Equal(t, "GetUser", attrs[graphQLOperationNameKey].AsString())
+	require.Equal(t, "query", attrs[graphQLOperationTypeKey].AsString())
+}
+
+func TestReadGraphQLOperation(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		want        *graphQLOperation
+	}{
+		{
+			name: "named query",
+			body: `{"query":"query GetUser { user { name } }"}`,
+			want: &graphQLOperation{name: "GetUser", typ: "query"},
+		},
+		{
+			name: "shorthand query",
+			body: `{"query":"{ user { name } }"}`,
+			want: &graphQLOperation{typ: "query"},
+		},
+		{
+			name: "anonymous mutation with variables",
+			body: `{"query":"mutation ($name: String = \"x\") { rename(name: $name) { id } }"}`,
+			want: &graphQLOperation{typ: "mutation"},
+		},
+		{
+			name: "selected operation",
+			body: `{"query":"# comment query Nope\nfragment F on User { name } query A { user { ...F } } mutation B @audit { reset }","operationName":"B"}`,
+			want: &graphQLOperation{name: "B", typ: "mutation"},
+		},
+		{
+			name: "ambiguous operation",
+			body: `{"query":"query A { a } query B { b }"}`,
+		},
+		{
+			name: "unknown operation",
+			body: `{"query":"query A { a }","operationName":"B"}`,
+		},
+		{
+			name:   "not a POST",
+			method: http.MethodGet,
+			body:   `{"query":"query A { a }"}`,
+		},
+		{
+			name: "other path",
+			path: "/api",
+			body: `{"query":"query A { a }"}`,
+		},
+		{
+			name:        "not JSON",
+			contentType: "application/graphql",
+			body:        `query A { a }`,
+		},
+		{
+			name: "too large",
+			body: `{"query":"query A { a }","padding":"` + strings.Repeat("x", maxGraphQLBodySize) + `"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, path, contentType := tt.method, tt.path, tt.contentType
+			if method == "" {
+				method = http.MethodPost
+			}
+			if path == "" {
+				path = "/graphql"
+			}
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			r := httptest.NewRequest(method, path, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", contentType)
+			require.Equal(t, tt.want, readGraphQLOperation(r, []string{"/graphql"}))
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, tt.body, string(body), "the body should be preserved")
+		})
+	}
+}
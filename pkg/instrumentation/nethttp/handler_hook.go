@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"net/http"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+// AfterNewHandler wraps the http.Handler returned by the target function with
+// NewHandlerMiddleware. It suits router builders returning a single handler,
+// e.g. with a rule such as:
+//
+//	router_hook:
+//		target: "github.com/foo/router"
+//		func: "NewRouter"
+//		after: "AfterNewHandler"
+//		path: "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/instrumentation/nethttp"
+func AfterNewHandler(ictx inst.HookContext, handler http.Handler) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseAfter)
+	wrapReturnedHandler(ictx, handler)
+}
+
+// AfterNewHandlerWithError is the same as AfterNewHandler for targets that
+// return (http.Handler, error), the handler is left untouched on error.
+func AfterNewHandlerWithError(ictx inst.HookContext, handler http.Handler, err error) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseAfter)
+	if err != nil {
+		return
+	}
+	wrapReturnedHandler(ictx, handler)
+}
+
+func wrapReturnedHandler(ictx inst.HookContext, handler http.Handler) {
+	if handler == nil || !instrumenter.RuleEnabled(ictx.GetRuleName()) {
+		return
+	}
+	ictx.SetReturnVal(0, NewHandlerMiddleware()(handler))
+}
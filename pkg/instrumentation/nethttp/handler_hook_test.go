@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst/insttest"
+)
+
+type router struct{}
+
+func (*router) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// newRouter stands for a framework function returning a handler
+func newRouter() http.Handler {
+	return &router{}
+}
+
+func TestAfterNewHandler(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	handler := newRouter()
+	ictx := &insttest.HookContext{ReturnVals: []interface{}{handler}}
+	AfterNewHandler(ictx, handler)
+	wrapped, ok := ictx.GetReturnVal(0).(http.Handler)
+	require.True(t, ok, "return value should still be a handler")
+
+	recorder := httptest.NewRecorder()
+	wrapped.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+}
+
+func TestAfterNewHandlerWithError(t *testing.T) {
+	handler := newRouter()
+	ictx := &insttest.HookContext{ReturnVals: []interface{}{handler, errors.New("failed")}}
+	AfterNewHandlerWithError(ictx, handler, errors.New("failed"))
+	require.Same(t, handler, ictx.GetReturnVal(0), "handler should be untouched on error")
+
+	ictx = &insttest.HookContext{ReturnVals: []interface{}{nil, nil}}
+	AfterNewHandlerWithError(ictx, nil, nil)
+	require.Nil(t, ictx.GetReturnVal(0), "nil handler should not be wrapped")
+}
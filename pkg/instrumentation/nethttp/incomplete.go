@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"runtime"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// incompleteKey flags spans ended by the safety net rather than by the after
+// hook of the call they record
+const incompleteKey = attribute.Key("instrumentation.incomplete")
+
+// endIfIncomplete is the safety net of the span of ctx, started by a before
+// hook that carries state to its after hook. The state only becomes
+// unreachable without the after hook having run when the hook was bypassed,
+// e.g. by a panic, the span is then ended once state is garbage collected
+// rather than leaked. Its end time is therefore late, the attribute tells it
+// apart. Calls that never return keep their state reachable.
+func endIfIncomplete[T any](ctx context.Context, state *T) {
+	runtime.SetFinalizer(state, func(*T) {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(incompleteKey.Bool(true))
+		span.End()
+	})
+}
+
+// completed disarms the safety net of state, the after hook ends the span
+func completed[T any](state *T) {
+	runtime.SetFinalizer(state, nil)
+}
+
+// unwinding reports whether its caller runs deferred while the goroutine
+// panics or exits through runtime.Goexit, i.e. whether the instrumented call
+// did not return normally. After hooks run deferred by the trampolines but not
+// directly, so they cannot recover the panic to tell.
+func unwinding() bool {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		switch frame.Function {
+		case "runtime.gopanic", "runtime.Goexit":
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
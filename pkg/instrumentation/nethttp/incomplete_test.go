@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst/insttest"
+)
+
+// requireEndedIncomplete collects the states dropped without their after hook
+// until the span is ended by the safety net
+func requireEndedIncomplete(t *testing.T, sr *tracetest.SpanRecorder) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return len(sr.Ended()) == 1
+	}, 5*time.Second, 10*time.Millisecond, "the span should be ended without its after hook")
+	require.Equal(t, true, spanAttrs(sr.Ended()[0])[incompleteKey].AsBool())
+}
+
+func TestServeHTTPHooksMissedEnd(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	// The after hook was bypassed, e.g. by a panic, the hook context is dropped
+	func() {
+		w, r := httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)
+		BeforeServeHTTP(&insttest.HookContext{Params: []interface{}{nil, w, r}}, nil, w, r)
+	}()
+	require.Empty(t, sr.Ended())
+	requireEndedIncomplete(t, sr)
+}
+
+func TestRoundTripHooksMissedEnd(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	func() {
+		r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		transport := &http.Transport{}
+		BeforeRoundTrip(&insttest.HookContext{Params: []interface{}{transport, r}}, transport, r)
+	}()
+	require.Empty(t, sr.Ended())
+	requireEndedIncomplete(t, sr)
+}
+
+func TestServeHTTPHooksCompleted(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	serveHTTP(http.NotFoundHandler(), httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	runtime.GC()
+	spans := sr.Ended()
+	require.Len(t, spans, 1, "the span should only be ended once")
+	require.NotContains(t, spanAttrs(spans[0]), incompleteKey)
+}
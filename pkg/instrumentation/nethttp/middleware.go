@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"fmt"
+	"net/http"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+// NewHandlerMiddleware returns a middleware recording a server span for every
+// request served by the wrapped handler. It is meant for applications that
+// are not built with compile-time instrumentation. Settings changed with
+// Configure apply to the requests served after them.
+func NewHandlerMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s := currentSettings()
+			if ignoredUserAgent(s, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			serverInstrumenter := getServerInstrumenter()
+			r = r.WithContext(withRouteCache(r.Context()))
+			request := HTTPServerRequest{Request: r, graphQL: readGraphQLOperation(r, s.GraphQLPaths)}
+			ctx := serverInstrumenter.Start(r.Context(), request)
+			rw := newResponseWriter(w)
+			// Routers record the matched pattern on the request they receive
+			request.Request = r.WithContext(ctx)
+			defer func() {
+				// A panicking handler aborts the request, its status is only
+				// known if it was written before
+				if p := recover(); p != nil {
+					// net/http does not log aborted requests either
+					if p != http.ErrAbortHandler {
+						getLogger().Error("recovered handler panic",
+							"panic", p, "method", r.Method, "path", r.URL.Path)
+					}
+					serverInstrumenter.End(ctx, instrumenter.Invocation[HTTPServerRequest, HTTPServerResponse]{
+						Request:  request,
+						Response: rw.response(),
+						Err:      fmt.Errorf("handler panic: %v", p),
+					})
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rw, request.Request)
+			if !rw.wroteHeader {
+				// net/http replies 200 OK once a handler returns without writing
+				rw.statusCode = http.StatusOK
+			}
+			serverInstrumenter.End(ctx, instrumenter.Invocation[HTTPServerRequest, HTTPServerResponse]{
+				Request:  request,
+				Response: rw.response(),
+			})
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/otelsetup"
+)
+
+func TestNewHandlerMiddleware(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	var handlerSpan trace.SpanContext
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})
+	server := httptest.NewServer(NewHandlerMiddleware()(mux))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/users/42")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, handlerSpan, span.SpanContext(), "handler should run within the server span")
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	require.Equal(t, http.MethodGet, attrs[semconv.HTTPRequestMethodKey].AsString())
+	require.Equal(t, "GET /users/{id}", attrs[semconv.HTTPRouteKey].AsString())
+	require.Equal(t, int64(http.StatusCreated), attrs[semconv.HTTPResponseStatusCodeKey].AsInt64())
+	require.Equal(t, int64(len("hello")), attrs[semconv.HTTPResponseBodySizeKey].AsInt64())
+}
+
+func TestNewHandlerMiddlewareWithoutWrite(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		statusCode int
+	}{
+		{
+			name:       "no write",
+			handler:    func(http.ResponseWriter, *http.Request) {},
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "no content",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name: "early hints",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusEarlyHints)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := useTracerProvider(t)
+
+			server := httptest.NewServer(NewHandlerMiddleware()(tt.handler))
+			defer server.Close()
+			resp, err := http.Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, tt.statusCode, resp.StatusCode)
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1, "span should be ended")
+			var statusCode int64
+			for _, attr := range spans[0].Attributes() {
+				if attr.Key == semconv.HTTPResponseStatusCodeKey {
+					statusCode = attr.Value.AsInt64()
+				}
+			}
+			require.Equal(t, int64(tt.statusCode), statusCode)
+		})
+	}
+}
+
+func TestNewHandlerMiddlewarePanic(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		statusCode int64
+		spanStatus codes.Code
+	}{
+		{
+			name: "before write",
+			handler: func(http.ResponseWriter, *http.Request) {
+				panic(http.ErrAbortHandler)
+			},
+			statusCode: 0,
+			spanStatus: codes.Error,
+		},
+		{
+			name: "after write",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic(http.ErrAbortHandler)
+			},
+			statusCode: http.StatusAccepted,
+			spanStatus: codes.Ok,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := useTracerProvider(t)
+
+			server := httptest.NewServer(NewHandlerMiddleware()(tt.handler))
+			defer server.Close()
+			resp, err := http.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1, "span should be ended")
+			require.Equal(t, tt.spanStatus, spans[0].Status().Code)
+			var statusCode int64
+			for _, attr := range spans[0].Attributes() {
+				if attr.Key == semconv.HTTPResponseStatusCodeKey {
+					statusCode = attr.Value.AsInt64()
+				}
+			}
+			require.Equal(t, tt.statusCode, statusCode)
+		})
+	}
+}
+
+func TestNewHandlerMiddlewarePanicLog(t *testing.T) {
+	var buf bytes.Buffer
+	otelsetup.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer otelsetup.SetLogger(nil)
+
+	handler := NewHandlerMiddleware()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+	require.PanicsWithValue(t, "boom", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	})
+	require.Contains(t, buf.String(), `level=ERROR msg="recovered handler panic" panic=boom method=GET path=/users`)
+
+	buf.Reset()
+	abortHandler := NewHandlerMiddleware()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	require.Panics(t, func() {
+		abortHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	require.Empty(t, buf.String(), "aborted requests should not be logged")
+}
+
+func TestNewHandlerMiddlewareErrorStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		spanStatus codes.Code
+	}{
+		{name: "client error", statusCode: http.StatusNotFound, spanStatus: codes.Unset},
+		{name: "server error", statusCode: http.StatusInternalServerError, spanStatus: codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := useTracerProvider(t)
+
+			server := httptest.NewServer(NewHandlerMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			})))
+			defer server.Close()
+			resp, err := http.Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			require.Equal(t, tt.spanStatus, spans[0].Status().Code)
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, attr := range spans[0].Attributes() {
+				attrs[attr.Key] = attr.Value
+			}
+			require.Equal(t, strconv.Itoa(tt.statusCode), attrs[semconv.ErrorTypeKey].AsString())
+		})
+	}
+}
+
+func TestNewHandlerMiddlewareIgnoredUserAgent(t *testing.T) {
+	configure(t, func(s *Settings) { s.IgnoredUserAgents = []string{"Pingdom"} })
+	sr := useTracerProvider(t)
+
+	handler := NewHandlerMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("User-Agent", "Pingdom.com_bot_version_1.4")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	require.Empty(t, sr.Ended(), "requests from an ignored user agent should not be recorded")
+}
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"net/http"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+// BeforeServeMuxRegister wraps the handlers registered on a http.ServeMux, so
+// that their requests record the registration pattern as http.route. The
+// target (*http.ServeMux).register backs Handle and HandleFunc as well as
+// http.Handle and http.HandleFunc.
+func BeforeServeMuxRegister(ictx inst.HookContext, _ *http.ServeMux, pattern string, handler http.Handler) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseBefore)
+	if !instrumenter.RuleEnabled(ictx.GetRuleName()) {
+		return
+	}
+	// ServeMux rejects nil handlers itself
+	if handler == nil {
+		return
+	}
+	if f, ok := handler.(http.HandlerFunc); ok && f == nil {
+		return
+	}
+	ictx.SetParam(2, newRouteHandler(pattern, handler))
+}
+
+// routeHandler records the pattern it was registered with as the route of the
+// requests it serves. Requests served by an instrumented server already have
+// a span, others are served through NewHandlerMiddleware.
+type routeHandler struct {
+	next         http.Handler
+	instrumented http.Handler
+}
+
+func newRouteHandler(pattern string, next http.Handler) *routeHandler {
+	recordRoute := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cache, ok := r.Context().Value(routeCacheKey{}).(*routeCache); ok {
+			cache.route = pattern
+		}
+		next.ServeHTTP(w, r)
+	})
+	return &routeHandler{
+		next:         recordRoute,
+		instrumented: NewHandlerMiddleware()(recordRoute),
+	}
+}
+
+func (h *routeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Value(routeCacheKey{}) != nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	h.instrumented.ServeHTTP(w, r)
+}
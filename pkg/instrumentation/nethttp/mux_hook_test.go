@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst/insttest"
+)
+
+// registerHooked mimics the trampoline of the hook around
+// (*http.ServeMux).register
+func registerHooked(mux *http.ServeMux, pattern string, handler http.Handler) http.Handler {
+	ictx := &insttest.HookContext{Params: []interface{}{mux, pattern, handler}}
+	BeforeServeMuxRegister(ictx, mux, pattern, handler)
+	registered := ictx.GetParam(2).(http.Handler)
+	mux.Handle(pattern, registered)
+	return registered
+}
+
+func TestServeMuxRegisterRoute(t *testing.T) {
+	const pattern = "GET /users/{id}"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("instrumented server", func(t *testing.T) {
+		sr := useTracerProvider(t)
+		mux := http.NewServeMux()
+		registerHooked(mux, pattern, handler)
+		serveHTTP(mux, httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+		spans := sr.Ended()
+		require.Len(t, spans, 1, "the server span should not be duplicated")
+		require.Contains(t, spans[0].Attributes(), semconv.HTTPRoute(pattern))
+	})
+
+	t.Run("uninstrumented server", func(t *testing.T) {
+		sr := useTracerProvider(t)
+		// Served without the mux, the route only comes from the registration
+		registered := registerHooked(http.NewServeMux(), pattern, handler)
+		recorder := httptest.NewRecorder()
+		registered.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		spans := sr.Ended()
+		require.Len(t, spans, 1)
+		require.Contains(t, spans[0].Attributes(), semconv.HTTPRoute(pattern))
+	})
+}
+
+func TestServeMuxRegisterNilHandler(t *testing.T) {
+	ictx := &insttest.HookContext{Params: []interface{}{nil, "/", http.HandlerFunc(nil)}}
+	BeforeServeMuxRegister(ictx, nil, "/", http.HandlerFunc(nil))
+	require.IsType(t, http.HandlerFunc(nil), ictx.GetParam(2), "nil handlers should be left to ServeMux")
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.24
+
+package nethttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+func TestServerNetworkProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   func(server *httptest.Server) *http.Client
+		version string
+	}{
+		{
+			name: "http/1.1",
+			start: func(server *httptest.Server) *http.Client {
+				server.Start()
+				return server.Client()
+			},
+			version: "1.1",
+		},
+		{
+			name: "h2 over tls",
+			start: func(server *httptest.Server) *http.Client {
+				server.EnableHTTP2 = true
+				server.StartTLS()
+				return server.Client()
+			},
+			version: "2",
+		},
+		{
+			name: "h2c",
+			start: func(server *httptest.Server) *http.Client {
+				server.Config.Protocols = new(http.Protocols)
+				server.Config.Protocols.SetUnencryptedHTTP2(true)
+				server.Start()
+				protocols := new(http.Protocols)
+				protocols.SetUnencryptedHTTP2(true)
+				return &http.Client{Transport: &http.Transport{Protocols: protocols}}
+			},
+			version: "2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := useTracerProvider(t)
+
+			handler := NewHandlerMiddleware()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			server := httptest.NewUnstartedServer(handler)
+			client := tt.start(server)
+			defer server.Close()
+
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			var version string
+			for _, attr := range spans[0].Attributes() {
+				if attr.Key == semconv.NetworkProtocolVersionKey {
+					version = attr.Value.AsString()
+				}
+			}
+			require.Equal(t, tt.version, version)
+		})
+	}
+}
+
+func TestProtocolVersion(t *testing.T) {
+	for proto, version := range map[string]string{
+		"HTTP/1.0": "1.0",
+		"HTTP/1.1": "1.1",
+		"HTTP/2.0": "2",
+		"HTTP/3.0": "3",
+		"HTTP/1.5": "",
+		"HTTP/4.0": "",
+	} {
+		major, minor, ok := http.ParseHTTPVersion(proto)
+		require.True(t, ok)
+		r := &http.Request{Proto: proto, ProtoMajor: major, ProtoMinor: minor}
+		require.Equal(t, version, protocolVersion(r), proto)
+	}
+	// HTTP/0.9 or a protocol that failed to parse
+	require.Empty(t, protocolVersion(&http.Request{Proto: "HTTP/0.9", ProtoMajor: 0, ProtoMinor: 9}))
+	require.Empty(t, protocolVersion(&http.Request{}))
+}
@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"net/http"
+)
+
+// responseWriter records the status code and the number of body bytes written
+// by the handler so that they can be reported when the span ends. The status
+// code is 0 until the handler writes the header or the body.
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	written     int64
+	wroteHeader bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	// Informational responses, e.g. 103 Early Hints, precede the final one
+	informational := statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols
+	if !w.wroteHeader && !informational {
+		w.statusCode = statusCode
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		// Writing the body implies a 200 OK header
+		w.statusCode = http.StatusOK
+		w.wroteHeader = true
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush or hijack the connection
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) response() HTTPServerResponse {
+	return HTTPServerResponse{
+		StatusCode: w.statusCode,
+		Header:     w.Header(),
+		BodySize:   w.written,
+	}
+}
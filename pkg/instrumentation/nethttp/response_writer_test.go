@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseWriterStatusCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		write       func(w http.ResponseWriter)
+		statusCode  int
+		wroteHeader bool
+	}{
+		{
+			name:       "nothing written",
+			write:      func(http.ResponseWriter) {},
+			statusCode: 0,
+		},
+		{
+			name:        "explicit 200",
+			write:       func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			statusCode:  http.StatusOK,
+			wroteHeader: true,
+		},
+		{
+			name:        "body only",
+			write:       func(w http.ResponseWriter) { _, _ = w.Write([]byte("hello")) },
+			statusCode:  http.StatusOK,
+			wroteHeader: true,
+		},
+		{
+			name: "header then body",
+			write: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte("hello"))
+			},
+			statusCode:  http.StatusNotFound,
+			wroteHeader: true,
+		},
+		{
+			name:       "informational only",
+			write:      func(w http.ResponseWriter) { w.WriteHeader(http.StatusEarlyHints) },
+			statusCode: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := newResponseWriter(httptest.NewRecorder())
+			tt.write(rw)
+			require.Equal(t, tt.wroteHeader, rw.wroteHeader)
+			require.Equal(t, tt.statusCode, rw.response().StatusCode)
+		})
+	}
+}
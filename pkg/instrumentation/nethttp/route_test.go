@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+// chiContextKey and chiRouteContext mimic chi, which stores a route context
+// under a pointer key and fills in the patterns while routing
+type chiContextKey struct{ name string }
+
+var chiRouteCtxKey = &chiContextKey{"RouteContext"}
+
+type chiRouteContext struct {
+	routePatterns []string
+}
+
+func (x *chiRouteContext) RoutePattern() string {
+	return strings.ReplaceAll(strings.Join(x.routePatterns, ""), "/*/", "/")
+}
+
+// chiRouter runs the middlewares before routing, as chi does
+type chiRouter struct {
+	middleware func(http.Handler) http.Handler
+}
+
+func (m *chiRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rctx := &chiRouteContext{}
+	r = r.WithContext(context.WithValue(r.Context(), chiRouteCtxKey, rctx))
+	m.middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		rctx.routePatterns = append(rctx.routePatterns, "/api/*", "/users/{id}")
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(w, r)
+}
+
+type gorillaRouteKey struct{}
+
+type gorillaRoute struct {
+	template string
+}
+
+func (r *gorillaRoute) GetPathTemplate() (string, error) {
+	if r.template == "" {
+		return "", errors.New("mux: route doesn't have a path")
+	}
+	return r.template, nil
+}
+
+func TestServerRouteFromContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []any
+		handler func(middleware func(http.Handler) http.Handler) http.Handler
+		route   string
+	}{
+		{
+			name: "chi route context",
+			keys: []any{chiRouteCtxKey},
+			handler: func(middleware func(http.Handler) http.Handler) http.Handler {
+				return &chiRouter{middleware: middleware}
+			},
+			route: "/api/users/{id}",
+		},
+		{
+			name: "gorilla route",
+			keys: []any{chiRouteCtxKey, gorillaRouteKey{}},
+			handler: func(middleware func(http.Handler) http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					route := &gorillaRoute{template: "/articles/{category}"}
+					r = r.WithContext(context.WithValue(r.Context(), gorillaRouteKey{}, route))
+					middleware(http.NotFoundHandler()).ServeHTTP(w, r)
+				})
+			},
+			route: "/articles/{category}",
+		},
+		{
+			name: "serve mux fallback",
+			keys: []any{chiRouteCtxKey},
+			handler: func(middleware func(http.Handler) http.Handler) http.Handler {
+				mux := http.NewServeMux()
+				mux.HandleFunc("GET /api/users/{id}", func(http.ResponseWriter, *http.Request) {})
+				return middleware(mux)
+			},
+			route: "GET /api/users/{id}",
+		},
+		{
+			name: "unconfigured key",
+			handler: func(middleware func(http.Handler) http.Handler) http.Handler {
+				return &chiRouter{middleware: middleware}
+			},
+			route: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := useTracerProvider(t)
+			configure(t, func(s *Settings) { s.RouteContextKeys = tt.keys })
+
+			handler := tt.handler(NewHandlerMiddleware())
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/42", nil))
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			require.Contains(t, spans[0].Attributes(), semconv.HTTPRoute(tt.route))
+		})
+	}
+}
+
+// routedRequest returns a request routed by a chi-like router, with the route
+// cache when cached is set
+func routedRequest(cached bool) *http.Request {
+	ctx := context.Background()
+	if cached {
+		ctx = withRouteCache(ctx)
+	}
+	rctx := &chiRouteContext{routePatterns: []string{"/api/*", "/users/{id}"}}
+	ctx = context.WithValue(ctx, chiRouteCtxKey, rctx)
+	return httptest.NewRequest(http.MethodGet, "/api/users/42", nil).WithContext(ctx)
+}
+
+func TestServerRouteCached(t *testing.T) {
+	getter := serverAttrsGetter{routeContextKeys: []any{chiRouteCtxKey}}
+	fresh := getter.GetHTTPRoute(HTTPServerRequest{Request: routedRequest(false)})
+	require.Equal(t, "/api/users/{id}", fresh)
+
+	request := HTTPServerRequest{Request: routedRequest(true)}
+	require.Equal(t, fresh, getter.GetHTTPRoute(request))
+	// Later lookups reuse the cached route rather than resolving it again
+	request.Request.Context().Value(chiRouteCtxKey).(*chiRouteContext).routePatterns = nil
+	require.Equal(t, fresh, getter.GetHTTPRoute(request))
+}
+
+func TestServerRouteNotCachedBeforeRouting(t *testing.T) {
+	getter := serverAttrsGetter{}
+	request := HTTPServerRequest{Request: httptest.NewRequest(http.MethodGet, "/api/users/42", nil)}
+	request.Request = request.Request.WithContext(withRouteCache(request.Request.Context()))
+	require.Empty(t, getter.GetHTTPRoute(request))
+	request.Request.Pattern = "GET /api/users/{id}"
+	require.Equal(t, "GET /api/users/{id}", getter.GetHTTPRoute(request))
+}
+
+func BenchmarkGetHTTPRoute(b *testing.B) {
+	getter := serverAttrsGetter{routeContextKeys: []any{gorillaRouteKey{}, chiRouteCtxKey}}
+	for _, cached := range []bool{false, true} {
+		name := "uncached"
+		if cached {
+			name = "cached"
+		}
+		b.Run(name, func(b *testing.B) {
+			request := HTTPServerRequest{Request: routedRequest(cached)}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				getter.GetHTTPRoute(request)
+			}
+		})
+	}
+}
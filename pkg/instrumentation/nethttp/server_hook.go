@@ -4,13 +4,73 @@
 package nethttp
 
 import (
-	"fmt"
+	"context"
 	"net/http"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
 )
 
+const serverStateKey = "nethttp.server"
+
+// getServerInstrumenter returns the instrumenter of served requests, built on
+// first use rather than at package init and rebuilt once the settings are
+// changed with Configure. It is safe for concurrent use.
+func getServerInstrumenter() *instrumenter.PropagatingFromUpstreamInstrumenter[HTTPServerRequest, HTTPServerResponse] {
+	return BuildServerInstrumenter()
+}
+
+// serverState is carried from BeforeServeHTTP to AfterServeHTTP
+type serverState struct {
+	ctx     context.Context
+	request HTTPServerRequest
+	writer  *responseWriter
+}
+
 func BeforeServeHTTP(ictx inst.HookContext, _ interface{}, w http.ResponseWriter, r *http.Request) {
-	fmt.Println("BeforeServeHTTP")
-	// TODO: Implement the real server hook logic here
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseBefore)
+	s := currentSettings()
+	if !instrumenter.RuleEnabled(ictx.GetRuleName()) || ignoredUserAgent(s, r) {
+		if s.RequestInterceptor != nil && s.RequestInterceptor(w, r) {
+			ictx.SetSkipCall(true)
+		}
+		return
+	}
+	r = r.WithContext(withRouteCache(r.Context()))
+	request := HTTPServerRequest{Request: r, graphQL: readGraphQLOperation(r, s.GraphQLPaths)}
+	ctx := getServerInstrumenter().Start(r.Context(), request)
+	rw := newResponseWriter(w)
+	request.Request = r.WithContext(ctx)
+	// The handler sees the capturing writer and the request within the span
+	ictx.SetParam(1, rw)
+	ictx.SetParam(2, request.Request)
+	state := &serverState{ctx: ctx, request: request, writer: rw}
+	endIfIncomplete(ctx, state)
+	ictx.SetKeyData(serverStateKey, state)
+	if s.RequestInterceptor != nil && s.RequestInterceptor(rw, request.Request) {
+		ictx.SetSkipCall(true)
+	}
+}
+
+func AfterServeHTTP(ictx inst.HookContext) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseAfter)
+	state, ok := ictx.GetKeyData(serverStateKey).(*serverState)
+	if !ok {
+		return
+	}
+	completed(state)
+	if !state.writer.wroteHeader {
+		if unwinding() {
+			// The handler panicked before writing, net/http aborts the
+			// response rather than replying 200 OK
+			state.writer.statusCode = http.StatusInternalServerError
+		} else {
+			// net/http replies 200 OK once a handler returns without writing
+			state.writer.statusCode = http.StatusOK
+		}
+	}
+	getServerInstrumenter().End(state.ctx, instrumenter.Invocation[HTTPServerRequest, HTTPServerResponse]{
+		Request:  state.request,
+		Response: state.writer.response(),
+	})
 }
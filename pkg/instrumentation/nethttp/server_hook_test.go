@@ -0,0 +1,305 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+	semconvnet "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/net"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst/insttest"
+)
+
+// useTracerProvider installs a TracerProvider recording the ended spans
+func useTracerProvider(t *testing.T) *tracetest.SpanRecorder {
+	sr := tracetest.NewSpanRecorder()
+	originalTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	// The cached instrumenters are bound to the replaced provider
+	instrumenter.InvalidateInstrumenters(instrumentationScope)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(originalTP)
+		instrumenter.InvalidateInstrumenters(instrumentationScope)
+	})
+	return sr
+}
+
+// configure changes the settings for the duration of the test
+func configure(t *testing.T, configure func(s *Settings)) {
+	original := *currentSettings()
+	Configure(configure)
+	t.Cleanup(func() { Configure(func(s *Settings) { *s = original }) })
+}
+
+// serveHTTP mimics the trampoline of the server hook around handler
+func serveHTTP(handler http.Handler, w http.ResponseWriter, r *http.Request) *insttest.HookContext {
+	ictx := &insttest.HookContext{Params: []interface{}{nil, w, r}}
+	BeforeServeHTTP(ictx, nil, w, r)
+	defer AfterServeHTTP(ictx)
+	if !ictx.IsSkipCall() {
+		handler.ServeHTTP(ictx.GetParam(1).(http.ResponseWriter), ictx.GetParam(2).(*http.Request))
+	}
+	return ictx
+}
+
+func TestServeHTTPHooks(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	var handlerSpan trace.SpanContext
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusAccepted)
+	})
+	recorder := httptest.NewRecorder()
+	ictx := serveHTTP(handler, recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.False(t, ictx.IsSkipCall())
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	require.Equal(t, spans[0].SpanContext(), handlerSpan, "handler should run within the server span")
+	require.Contains(t, spans[0].Attributes(), semconv.HTTPResponseStatusCode(http.StatusAccepted))
+}
+
+func TestServeHTTPHooksPanickingHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+	}{
+		{
+			name:       "nothing written",
+			handler:    func(http.ResponseWriter, *http.Request) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "header written",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("boom")
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "aborted",
+			handler:    func(http.ResponseWriter, *http.Request) { panic(http.ErrAbortHandler) },
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := useTracerProvider(t)
+
+			require.Panics(t, func() {
+				serveHTTP(tt.handler, httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			})
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			require.Contains(t, spans[0].Attributes(), semconv.HTTPResponseStatusCode(tt.wantStatus))
+		})
+	}
+}
+
+func TestServeHTTPHooksQueryShape(t *testing.T) {
+	sr := useTracerProvider(t)
+	serveHTTP(http.NotFoundHandler(), httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/search?q=secret&page=2", nil))
+	require.Contains(t, sr.Ended()[0].Attributes(), semconv.URLQuery("q=secret&page=2"))
+
+	// Settings changed after the first request apply to the next ones
+	configure(t, func(s *Settings) { s.RecordQueryShape = true })
+	serveHTTP(http.NotFoundHandler(), httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/search?q=secret&page=2", nil))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	attrs := spans[1].Attributes()
+	require.Contains(t, attrs, semconvnet.URLQueryParamCountKey.Int(2))
+	require.Contains(t, attrs, semconvnet.URLQueryLengthKey.Int(len("q=secret&page=2")))
+	for _, attr := range attrs {
+		require.NotEqual(t, semconv.URLQueryKey, attr.Key, "the raw query should not be recorded")
+	}
+}
+
+func TestServeHTTPHooksIgnoreIncomingTraceContext(t *testing.T) {
+	sr := useTracerProvider(t)
+	originalPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	defer otel.SetTextMapPropagator(originalPropagator)
+	configure(t, func(s *Settings) { s.IgnoreIncomingTraceContext = true })
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	var member string
+	handler := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		member = baggage.FromContext(r.Context()).Member("tenant").Value()
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	req.Header.Set("baggage", "tenant=acme")
+	serveHTTP(handler, httptest.NewRecorder(), req)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	require.NotEqual(t, traceID, spans[0].SpanContext().TraceID().String(), "a new trace should be started")
+	require.False(t, spans[0].Parent().IsValid(), "the server span should be a root")
+	require.Equal(t, "acme", member, "the baggage should still be extracted")
+}
+
+func TestServeHTTPHooksInterceptor(t *testing.T) {
+	sr := useTracerProvider(t)
+	configure(t, func(s *Settings) {
+		s.RequestInterceptor = func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Header.Get("Authorization") != "" {
+				return false
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return true
+		}
+	})
+
+	handlerCalled := false
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { handlerCalled = true })
+	recorder := httptest.NewRecorder()
+	ictx := serveHTTP(handler, recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.True(t, ictx.IsSkipCall(), "the handled request should skip the handler")
+	require.False(t, handlerCalled)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1, "the after hook should end the span")
+	require.Contains(t, spans[0].Attributes(), semconv.HTTPResponseStatusCode(http.StatusUnauthorized))
+}
+
+func TestServeHTTPHooksIgnoredUserAgent(t *testing.T) {
+	configure(t, func(s *Settings) {
+		s.IgnoredUserAgents = []string{"kube-probe"}
+		s.IgnoredUserAgentPattern = regexp.MustCompile(`(?i)bot\b`)
+	})
+
+	tests := []struct {
+		userAgent string
+		ignored   bool
+	}{
+		{userAgent: "Kube-Probe/1.30", ignored: true},
+		{userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1)", ignored: true},
+		{userAgent: "Mozilla/5.0 (X11; Linux x86_64)"},
+		{userAgent: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.userAgent, func(t *testing.T) {
+			sr := useTracerProvider(t)
+			handlerCalled := false
+			handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { handlerCalled = true })
+			r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			r.Header.Set("User-Agent", tt.userAgent)
+			serveHTTP(handler, httptest.NewRecorder(), r)
+			require.True(t, handlerCalled, "the handler should serve ignored requests too")
+			if tt.ignored {
+				require.Empty(t, sr.Ended(), "requests from an ignored user agent should not be recorded")
+			} else {
+				require.Len(t, sr.Ended(), 1)
+			}
+		})
+	}
+}
+
+func TestServerInstrumenterConcurrentInit(t *testing.T) {
+	useTracerProvider(t)
+
+	// Requests may be served concurrently before any instrumenter exists, run
+	// with -race to detect unsynchronized initialization
+	const goroutines = 8
+	servers := make([]interface{}, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			servers[i] = getServerInstrumenter()
+			require.NotNil(t, BuildServerInstrumenter())
+			require.NotNil(t, BuildClientInstrumenter())
+		}()
+	}
+	wg.Wait()
+	for _, server := range servers {
+		require.NotNil(t, server)
+		require.Same(t, servers[0], server, "the server instrumenter should be built once")
+	}
+}
+
+func TestConfigureConcurrentRequests(t *testing.T) {
+	sr := useTracerProvider(t)
+	configure(t, func(*Settings) {})
+
+	// Settings change while requests are served, run with -race to detect
+	// unsynchronized reads
+	const requests = 8
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodGet, "/search?q=secret", nil)
+			r.Header.Set("User-Agent", "Mozilla/5.0")
+			serveHTTP(http.NotFoundHandler(), httptest.NewRecorder(), r)
+		}()
+		Configure(func(s *Settings) {
+			s.RecordQueryShape = i%2 == 0
+			s.IgnoredUserAgents = []string{"kube-probe"}
+		})
+	}
+	wg.Wait()
+	require.Len(t, sr.Ended(), requests)
+}
+
+func TestServeHTTPHooksBodySizeMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	originalMP := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(originalMP) })
+	useTracerProvider(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte("hello, world"))
+	})
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=gopher"))
+	serveHTTP(handler, httptest.NewRecorder(), r)
+
+	rm := &metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(context.Background(), rm))
+	sizes := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if hist, ok := m.Data.(metricdata.Histogram[int64]); ok {
+				require.Len(t, hist.DataPoints, 1)
+				sizes[m.Name] = hist.DataPoints[0].Sum
+			}
+		}
+	}
+	require.Equal(t, map[string]int64{
+		"http.server.request.body.size":  int64(len("name=gopher")),
+		"http.server.response.body.size": int64(len("hello, world")),
+	}, sizes)
+}
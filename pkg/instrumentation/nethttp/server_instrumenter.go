@@ -0,0 +1,435 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+	semconvhttp "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/http"
+	semconvnet "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/net"
+)
+
+// ignoredUserAgent reports whether r is served without a span
+func ignoredUserAgent(s *Settings, r *http.Request) bool {
+	if len(s.IgnoredUserAgents) == 0 && s.IgnoredUserAgentPattern == nil {
+		return false
+	}
+	userAgent := r.UserAgent()
+	if userAgent == "" {
+		return false
+	}
+	if s.IgnoredUserAgentPattern != nil && s.IgnoredUserAgentPattern.MatchString(userAgent) {
+		return true
+	}
+	userAgent = strings.ToLower(userAgent)
+	for _, ignored := range s.IgnoredUserAgents {
+		if ignored != "" && strings.Contains(userAgent, strings.ToLower(ignored)) {
+			return true
+		}
+	}
+	return false
+}
+
+// HTTPServerRequest is the request served by the instrumented handler.
+type HTTPServerRequest struct {
+	Request *http.Request
+
+	// graphQL is the operation of GraphQL requests to Settings.GraphQLPaths
+	graphQL *graphQLOperation
+}
+
+// HTTPServerResponse is what the handler wrote back, as seen by the
+// capturing response writer.
+type HTTPServerResponse struct {
+	StatusCode int
+	Header     http.Header
+	BodySize   int64
+}
+
+type serverAttrsGetter struct {
+	trustedProxies   []netip.Prefix
+	routeContextKeys []any
+}
+
+func (serverAttrsGetter) GetRequestMethod(request HTTPServerRequest) string {
+	// An empty method means GET, as for the server handling the request
+	if request.Request.Method == "" {
+		return http.MethodGet
+	}
+	return request.Request.Method
+}
+
+func (serverAttrsGetter) GetHTTPRequestHeader(request HTTPServerRequest, name string) []string {
+	return request.Request.Header.Values(name)
+}
+
+func (serverAttrsGetter) GetHTTPResponseStatusCode(_ HTTPServerRequest, response HTTPServerResponse, _ error) int {
+	return response.StatusCode
+}
+
+func (serverAttrsGetter) GetHTTPResponseHeader(_ HTTPServerRequest, response HTTPServerResponse, name string) []string {
+	return response.Header.Values(name)
+}
+
+// isErrorStatusCode reports whether statusCode is a 4xx or 5xx status code,
+// codes from 600 on are not defined
+func isErrorStatusCode(statusCode int) bool {
+	return statusCode >= http.StatusBadRequest && statusCode < 600
+}
+
+// errorType returns the error.type of err, which is also a metric attribute
+// and must have a low cardinality: "timeout" for timeouts, the Go type of the
+// error otherwise. Errors without a distinctive type, as errors.New and
+// fmt.Errorf return, are reported as _OTHER. The *url.Error wrapping every
+// client error is skipped for the error it wraps.
+func errorType(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Err != nil {
+		err = urlErr.Err
+	}
+	t := fmt.Sprintf("%T", err)
+	switch t {
+	case "*errors.errorString", "*errors.joinError", "*fmt.wrapError", "*fmt.wrapErrors":
+		return "_OTHER"
+	}
+	return t
+}
+
+// GetErrorType classifies both 4xx and 5xx responses, the span status
+// extractor only marks the latter as errors as 4xx are caused by the client
+func (serverAttrsGetter) GetErrorType(_ HTTPServerRequest, response HTTPServerResponse, err error) string {
+	if isErrorStatusCode(response.StatusCode) {
+		return strconv.Itoa(response.StatusCode)
+	}
+	if err != nil {
+		return errorType(err)
+	}
+	return ""
+}
+
+type routeCacheKey struct{}
+
+// routeCache holds the route of a request once resolved, so that the span
+// name, the http.route attribute and any later lookup share it
+type routeCache struct {
+	route string
+}
+
+// withRouteCache prepares ctx to cache the route of the request served within
+// it, the route is resolved on first use after routing
+func withRouteCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeCacheKey{}, &routeCache{})
+}
+
+func (g serverAttrsGetter) GetHTTPRoute(request HTTPServerRequest) string {
+	cache, _ := request.Request.Context().Value(routeCacheKey{}).(*routeCache)
+	if cache != nil && cache.route != "" {
+		return cache.route
+	}
+	route := g.resolveHTTPRoute(request.Request)
+	// No route is known before the request is routed, it is not cached
+	if cache != nil {
+		cache.route = route
+	}
+	return route
+}
+
+func (g serverAttrsGetter) resolveHTTPRoute(r *http.Request) string {
+	ctx := r.Context()
+	for _, key := range g.routeContextKeys {
+		if route := routeFromContextValue(ctx.Value(key)); route != "" {
+			return route
+		}
+	}
+	return r.Pattern
+}
+
+// routeFromContextValue returns the route provided by the value that a
+// router stored in the request context
+func routeFromContextValue(value any) string {
+	switch v := value.(type) {
+	case interface{ RoutePattern() string }:
+		return v.RoutePattern()
+	case interface{ GetPathTemplate() (string, error) }:
+		if template, err := v.GetPathTemplate(); err == nil {
+			return template
+		}
+	case string:
+		return v
+	}
+	return ""
+}
+
+func (serverAttrsGetter) GetHTTPRequestBodySize(request HTTPServerRequest) int64 {
+	return request.Request.ContentLength
+}
+
+func (serverAttrsGetter) GetHTTPResponseBodySize(_ HTTPServerRequest, response HTTPServerResponse) int64 {
+	return response.BodySize
+}
+
+func (serverAttrsGetter) GetNetworkType(request HTTPServerRequest, _ HTTPServerResponse) string {
+	host, _ := splitHostPort(request.Request.RemoteAddr)
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil:
+		return "ipv4"
+	default:
+		return "ipv6"
+	}
+}
+
+func (serverAttrsGetter) GetNetworkTransport(request HTTPServerRequest, _ HTTPServerResponse) string {
+	// HTTP/3 runs over QUIC
+	if request.Request.ProtoMajor == 3 {
+		return "udp"
+	}
+	return "tcp"
+}
+
+func (serverAttrsGetter) GetNetworkProtocolName(HTTPServerRequest, HTTPServerResponse) string {
+	return "http"
+}
+
+func (serverAttrsGetter) GetNetworkProtocolVersion(request HTTPServerRequest, _ HTTPServerResponse) string {
+	return protocolVersion(request.Request)
+}
+
+func (serverAttrsGetter) GetNetworkLocalInetAddress(request HTTPServerRequest, _ HTTPServerResponse) string {
+	host, _ := splitHostPort(localAddr(request.Request))
+	return host
+}
+
+func (serverAttrsGetter) GetNetworkLocalPort(request HTTPServerRequest, _ HTTPServerResponse) int {
+	_, port := splitHostPort(localAddr(request.Request))
+	return port
+}
+
+func (serverAttrsGetter) GetNetworkPeerInetAddress(request HTTPServerRequest, _ HTTPServerResponse) string {
+	host, _ := splitHostPort(request.Request.RemoteAddr)
+	return host
+}
+
+func (serverAttrsGetter) GetNetworkPeerPort(request HTTPServerRequest, _ HTTPServerResponse) int {
+	_, port := splitHostPort(request.Request.RemoteAddr)
+	return port
+}
+
+func (g serverAttrsGetter) GetURLScheme(request HTTPServerRequest) string {
+	if g.isTrustedProxy(request.Request.RemoteAddr) {
+		// Proxies may append to the header, the client facing one comes first
+		proto, _, _ := strings.Cut(request.Request.Header.Get("X-Forwarded-Proto"), ",")
+		if proto = strings.ToLower(strings.TrimSpace(proto)); proto != "" {
+			return proto
+		}
+	}
+	if request.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+func (g serverAttrsGetter) GetClientAddress(request HTTPServerRequest) string {
+	if address := g.forwardedFor(request.Request); address != "" {
+		return address
+	}
+	host, _ := splitHostPort(request.Request.RemoteAddr)
+	return host
+}
+
+func (g serverAttrsGetter) GetClientPort(request HTTPServerRequest) int {
+	// The port of a forwarded client is unknown
+	if g.forwardedFor(request.Request) != "" {
+		return 0
+	}
+	_, port := splitHostPort(request.Request.RemoteAddr)
+	return port
+}
+
+// forwardedFor returns the client address from X-Forwarded-For, i.e. the
+// rightmost entry that is not a trusted proxy, or an empty string when the
+// immediate peer is not trusted
+func (g serverAttrsGetter) forwardedFor(r *http.Request) string {
+	if !g.isTrustedProxy(r.RemoteAddr) {
+		return ""
+	}
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	for i := len(hops) - 1; i > 0; i-- {
+		if !g.isTrustedProxy(hops[i]) {
+			return hops[i]
+		}
+	}
+	if len(hops) > 0 {
+		return hops[0]
+	}
+	return ""
+}
+
+// isTrustedProxy reports whether address, with or without a port, is within
+// the trusted proxy ranges
+func (g serverAttrsGetter) isTrustedProxy(address string) bool {
+	if len(g.trustedProxies) == 0 {
+		return false
+	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range g.trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (serverAttrsGetter) GetURLPath(request HTTPServerRequest) string {
+	if request.Request.URL == nil {
+		return ""
+	}
+	return request.Request.URL.Path
+}
+
+func (serverAttrsGetter) GetURLQuery(request HTTPServerRequest) string {
+	if request.Request.URL == nil {
+		return ""
+	}
+	return request.Request.URL.RawQuery
+}
+
+// protocolVersion maps the request protocol to network.protocol.version,
+// multiplexed protocols are reported by their major version only, i.e. "2"
+// for both h2 and h2c and "3" for HTTP/3. Protocols that cannot be determined
+// confidently, e.g. HTTP/0.9 or unknown versions, are omitted.
+func protocolVersion(r *http.Request) string {
+	switch {
+	case r.ProtoMajor == 1 && (r.ProtoMinor == 0 || r.ProtoMinor == 1):
+		return "1." + strconv.Itoa(r.ProtoMinor)
+	case (r.ProtoMajor == 2 || r.ProtoMajor == 3) && r.ProtoMinor == 0:
+		return strconv.Itoa(r.ProtoMajor)
+	default:
+		return ""
+	}
+}
+
+func localAddr(r *http.Request) string {
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		return addr.String()
+	}
+	return ""
+}
+
+func splitHostPort(hostPort string) (string, int) {
+	host, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return "", 0
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return host, 0
+	}
+	return host, port
+}
+
+// BuildServerInstrumenter builds the instrumenter of incoming requests, the
+// remote span context is extracted from the request headers.
+// Repeated calls return the same instrumenter until the settings are changed
+// with Configure.
+func BuildServerInstrumenter() *instrumenter.PropagatingFromUpstreamInstrumenter[HTTPServerRequest, HTTPServerResponse] {
+	return instrumenter.GetOrBuildInstrumenter(instrumentationScope, buildServerInstrumenter)
+}
+
+func buildServerInstrumenter() *instrumenter.PropagatingFromUpstreamInstrumenter[HTTPServerRequest, HTTPServerResponse] {
+	s := currentSettings()
+	getter := serverAttrsGetter{trustedProxies: s.TrustedProxies, routeContextKeys: s.RouteContextKeys}
+	networkExtractor := semconvnet.CreateNetworkAttributesExtractor[HTTPServerRequest, HTTPServerResponse](getter)
+	clientExtractor := semconvnet.CreateClientAttributesExtractor[HTTPServerRequest, HTTPServerResponse](getter)
+	builder := &instrumenter.Builder[HTTPServerRequest, HTTPServerResponse]{}
+	builder.Init()
+	registry := semconvhttp.NewMetricsRegistry(getLogger(), meter())
+	registry.SetAttributeKeys(s.MetricAttributeKeys...)
+	if serverMetrics, err := registry.NewHTTPServerMetric(instrumentationName); err == nil {
+		builder.AddOperationListeners(serverMetrics)
+	}
+	if s.EndUserContextKey != nil {
+		builder.AddAttributesExtractor(endUserAttrsExtractor{key: s.EndUserContextKey, hash: s.HashEndUserID})
+	}
+	var spanNameExtractor instrumenter.SpanNameExtractor[HTTPServerRequest] = &semconvhttp.HTTPServerSpanNameExtractor[HTTPServerRequest, HTTPServerResponse]{
+		Getter: getter,
+	}
+	if len(s.GraphQLPaths) > 0 {
+		spanNameExtractor = graphQLSpanNameExtractor{base: spanNameExtractor}
+		builder.AddAttributesExtractor(graphQLAttrsExtractor{})
+	}
+	prop := otel.GetTextMapPropagator()
+	if s.IgnoreIncomingTraceContext {
+		prop = untrustedParentPropagator{TextMapPropagator: prop}
+	}
+	return builder.
+		SetSpanNameExtractor(spanNameExtractor).
+		SetSpanKindExtractor(&instrumenter.AlwaysServerExtractor[HTTPServerRequest]{}).
+		SetSpanStatusExtractor(semconvhttp.HTTPServerSpanStatusExtractor[HTTPServerRequest, HTTPServerResponse]{
+			Getter:       getter,
+			StatusMapper: s.SpanStatusMapper,
+		}).
+		AddAttributesExtractor(&semconvhttp.HTTPServerAttrsExtractor[
+			HTTPServerRequest, HTTPServerResponse, serverAttrsGetter,
+		]{
+			Base: semconvhttp.HTTPCommonAttrsExtractor[HTTPServerRequest, HTTPServerResponse, serverAttrsGetter]{
+				HTTPGetter:   getter,
+				KnownMethods: s.KnownMethods,
+			},
+		}, &networkExtractor, &clientExtractor, &semconvnet.URLAttrsExtractor[HTTPServerRequest, HTTPServerResponse, serverAttrsGetter]{
+			Getter:           getter,
+			RecordQueryShape: s.RecordQueryShape,
+		}).
+		SetInstrumentEnabler(instrumenter.NewEnvInstrumentEnabler("nethttp")).
+		SetInstrumentationScope(instrumentationScope).
+		BuildPropagatingFromUpstreamInstrumenter(func(request HTTPServerRequest) propagation.TextMapCarrier {
+			return propagation.HeaderCarrier(request.Request.Header)
+		}, prop)
+}
+
+// untrustedParentPropagator drops the span context extracted by the
+// propagator it wraps, so the server span does not join the trace of clients
+type untrustedParentPropagator struct {
+	propagation.TextMapPropagator
+}
+
+func (p untrustedParentPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return trace.ContextWithSpan(p.TextMapPropagator.Extract(ctx, carrier), trace.SpanFromContext(ctx))
+}
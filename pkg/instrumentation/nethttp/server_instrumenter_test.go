@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+// httptest.NewRequest requests come from 192.0.2.1:1234
+var testTrustedProxies = []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24"), netip.MustParsePrefix("10.0.0.0/8")}
+
+func TestServerErrorType(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		err        error
+		want       string
+	}{
+		{statusCode: http.StatusOK},
+		{statusCode: 399},
+		{statusCode: http.StatusBadRequest, want: "400"},
+		{statusCode: http.StatusNotFound, want: "404"},
+		{statusCode: http.StatusInternalServerError, want: "500"},
+		{statusCode: 599, want: "599"},
+		{statusCode: 600},
+		{statusCode: http.StatusOK, err: errors.New("write: broken pipe"), want: "_OTHER"},
+		{statusCode: 600, err: fmt.Errorf("serve: %w", errors.New("write: broken pipe")), want: "_OTHER"},
+		{statusCode: http.StatusOK, err: &net.OpError{Op: "write", Err: syscall.EPIPE}, want: "*net.OpError"},
+		{statusCode: http.StatusOK, err: fmt.Errorf("serve: %w", context.DeadlineExceeded), want: "timeout"},
+		{statusCode: http.StatusOK, err: os.ErrDeadlineExceeded, want: "timeout"},
+		{statusCode: http.StatusServiceUnavailable, err: errors.New("write: broken pipe"), want: "503"},
+	}
+	for _, tt := range tests {
+		response := HTTPServerResponse{StatusCode: tt.statusCode}
+		got := serverAttrsGetter{}.GetErrorType(HTTPServerRequest{}, response, tt.err)
+		require.Equal(t, tt.want, got, "status code %d, error %v", tt.statusCode, tt.err)
+	}
+}
+
+func TestServerURLScheme(t *testing.T) {
+	tests := []struct {
+		name           string
+		tls            bool
+		forwardedProto string
+		trust          bool
+		scheme         string
+	}{
+		{name: "plain", scheme: "http"},
+		{name: "direct tls", tls: true, scheme: "https"},
+		{name: "forwarded proto trusted", forwardedProto: "HTTPS", trust: true, scheme: "https"},
+		{name: "forwarded proto chain", forwardedProto: "https, http", trust: true, scheme: "https"},
+		{name: "forwarded proto not configured", forwardedProto: "https", scheme: "http"},
+		{name: "trusted without header", tls: true, trust: true, scheme: "https"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+			if tt.forwardedProto != "" {
+				r.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+			}
+			getter := serverAttrsGetter{}
+			if tt.trust {
+				getter.trustedProxies = testTrustedProxies
+			}
+			require.Equal(t, tt.scheme, getter.GetURLScheme(HTTPServerRequest{Request: r}))
+		})
+	}
+}
+
+func TestServerForwardedHeadersUntrustedPeer(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:4321"
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	request := HTTPServerRequest{Request: r}
+	getter := serverAttrsGetter{trustedProxies: testTrustedProxies}
+	require.Equal(t, "http", getter.GetURLScheme(request))
+	require.Equal(t, "203.0.113.7", getter.GetClientAddress(request))
+	require.Equal(t, 4321, getter.GetClientPort(request))
+}
+
+func TestServerClientAddress(t *testing.T) {
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		address      string
+		port         int
+	}{
+		{name: "no header", remoteAddr: "192.0.2.1:1234", address: "192.0.2.1", port: 1234},
+		{name: "trusted peer", remoteAddr: "192.0.2.1:1234", forwardedFor: []string{"198.51.100.1"}, address: "198.51.100.1"},
+		{
+			name:         "trusted hops are skipped",
+			remoteAddr:   "192.0.2.1:1234",
+			forwardedFor: []string{"203.0.113.9, 198.51.100.1", "10.1.2.3"},
+			address:      "198.51.100.1",
+		},
+		{
+			name:         "only trusted hops",
+			remoteAddr:   "192.0.2.1:1234",
+			forwardedFor: []string{"10.0.0.1, 10.0.0.2"},
+			address:      "10.0.0.1",
+		},
+		{
+			name:         "untrusted peer",
+			remoteAddr:   "203.0.113.7:4321",
+			forwardedFor: []string{"198.51.100.1"},
+			address:      "203.0.113.7",
+			port:         4321,
+		},
+		{
+			name:         "ipv4-mapped trusted peer",
+			remoteAddr:   "[::ffff:192.0.2.1]:1234",
+			forwardedFor: []string{"198.51.100.1"},
+			address:      "198.51.100.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			request := HTTPServerRequest{Request: r}
+			getter := serverAttrsGetter{trustedProxies: testTrustedProxies}
+			require.Equal(t, tt.address, getter.GetClientAddress(request))
+			require.Equal(t, tt.port, getter.GetClientPort(request))
+		})
+	}
+}
+
+func TestServerEmptyRequestMethod(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Method = ""
+	request := HTTPServerRequest{Request: r}
+	serverInstrumenter := BuildServerInstrumenter()
+	ctx := serverInstrumenter.Start(r.Context(), request)
+	serverInstrumenter.End(ctx, instrumenter.Invocation[HTTPServerRequest, HTTPServerResponse]{
+		Request:  request,
+		Response: HTTPServerResponse{StatusCode: http.StatusOK},
+	})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, http.MethodGet, spans[0].Name())
+	attrs := make(map[string]string)
+	for _, attr := range spans[0].Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	require.Equal(t, http.MethodGet, attrs[string(semconv.HTTPRequestMethodKey)])
+	_, ok := attrs[string(semconv.HTTPRequestMethodOriginalKey)]
+	require.False(t, ok)
+
+	// Clients record the method as set on the request
+	require.Empty(t, clientAttrsGetter{}.GetRequestMethod(HTTPClientRequest{Request: r}))
+}
+
+func TestServerURLSchemeOverTLS(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	server := httptest.NewTLSServer(NewHandlerMiddleware()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + "/path?q=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	attrs := make(map[string]string)
+	for _, attr := range spans[0].Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	require.Equal(t, "https", attrs[string(semconv.URLSchemeKey)])
+	require.Equal(t, "/path", attrs[string(semconv.URLPathKey)])
+	require.Equal(t, "q=1", attrs[string(semconv.URLQueryKey)])
+}
@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"net/http"
+	"net/netip"
+	"regexp"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+	semconvhttp "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/http"
+)
+
+// Settings configures the server and client instrumentation, it is changed
+// with Configure. The zero value records the semconv defaults.
+type Settings struct {
+	// KnownMethods overrides the HTTP methods recorded verbatim by the server
+	// instrumenter, e.g. to allow WebDAV methods. Other methods are recorded as
+	// _OTHER. The standard semconv methods are used when empty.
+	KnownMethods []string
+
+	// SpanStatusMapper overrides which responses mark the server and client
+	// spans as errors, e.g. to treat 404 as an error. The semconv rules are
+	// used when nil.
+	SpanStatusMapper semconvhttp.SpanStatusMapper
+
+	// MetricAttributeKeys restricts the attributes of the HTTP metrics, e.g.
+	// to http.request.method and http.response.status_code. Spans keep all
+	// their attributes. The semconv metric attributes are recorded when empty.
+	MetricAttributeKeys []attribute.Key
+
+	// TrustedProxies lists the address ranges of the proxies in front of the
+	// server. The X-Forwarded-For and X-Forwarded-Proto headers are only
+	// honored for client.address and url.scheme when the immediate peer is
+	// within one of them, as they can be forged by clients. Forwarded headers
+	// are ignored when empty.
+	TrustedProxies []netip.Prefix
+
+	// RouteContextKeys lists the request context keys under which third-party
+	// routers store the matched route, e.g. chi.RouteCtxKey. The value under a
+	// key provides the route through a RoutePattern() string method as chi
+	// does, a GetPathTemplate() (string, error) method as gorilla/mux routes
+	// do, or is the route string itself. The ServeMux pattern is used when no
+	// key yields a route.
+	RouteContextKeys []any
+
+	// RecordQueryShape records the number of query parameters and the length
+	// of the query of server requests as url.query.param_count and
+	// url.query.length instead of url.query, so that no query value is
+	// recorded.
+	RecordQueryShape bool
+
+	// IgnoreIncomingTraceContext starts a new trace for every server request,
+	// ignoring the span context sent by clients, e.g. for servers at an
+	// untrusted edge. Other values extracted by the global propagator, such as
+	// baggage, are kept.
+	IgnoreIncomingTraceContext bool
+
+	// IgnoredUserAgents lists User-Agent substrings, matched
+	// case-insensitively, of the requests served without a span, e.g.
+	// synthetic monitors and health checkers. No request is ignored when
+	// empty.
+	IgnoredUserAgents []string
+
+	// IgnoredUserAgentPattern ignores the requests whose User-Agent it
+	// matches, in addition to IgnoredUserAgents. No request is ignored when
+	// nil.
+	IgnoredUserAgentPattern *regexp.Regexp
+
+	// EndUserContextKey is the request context key under which the
+	// application stores the authenticated user ID, as a string or a
+	// fmt.Stringer. The ID is recorded as enduser.id on server spans when set,
+	// nothing is recorded by default.
+	EndUserContextKey any
+
+	// HashEndUserID records the hex-encoded SHA-256 hash of the user ID
+	// instead of the ID itself, as it may be personal data.
+	HashEndUserID bool
+
+	// GraphQLPaths lists the paths of the GraphQL endpoints, e.g. "/graphql".
+	// The operation of JSON POST requests to them is recorded as
+	// graphql.operation.name and graphql.operation.type and names the server
+	// span, e.g. "query GetUser". Request bodies are not read when empty.
+	GraphQLPaths []string
+
+	// RequestInterceptor runs before the handler of every request served by
+	// the instrumented server, with the span already started unless the
+	// request is ignored for its User-Agent. It returns true when it responded
+	// to the request itself, e.g. to reject it with 401, in which case the
+	// handler is skipped and the span ends with the status it wrote.
+	RequestInterceptor func(w http.ResponseWriter, r *http.Request) (handled bool)
+
+	// CapturedResponseHeaders lists the response headers recorded on client
+	// spans as http.response.header.<name> attributes, e.g. Content-Encoding
+	// to tell compressed bodies apart. No header is recorded when empty, the
+	// media type of the response is recorded as http.response.content_type
+	// regardless.
+	CapturedResponseHeaders []string
+
+	// ClientSpanNameWithServerAddress names client spans
+	// {method} {server.address}, e.g. "GET example.com", rather than {method}
+	// alone as semconv recommends.
+	ClientSpanNameWithServerAddress bool
+}
+
+var (
+	settings    atomic.Pointer[Settings]
+	configureMu sync.Mutex
+	// defaultSettings are used until Configure is first called
+	defaultSettings Settings
+)
+
+// Configure changes the settings of the instrumentation, configure is called
+// with a copy of the current settings to modify, e.g.
+//
+//	nethttp.Configure(func(s *nethttp.Settings) { s.RecordQueryShape = true })
+//
+// The changes apply to the requests started once it returns, the server and
+// client instrumenters are rebuilt with them on their next use. Concurrent
+// calls are serialized. The slices of the settings are shared with the
+// requests in flight, they must be replaced rather than modified in place.
+func Configure(configure func(s *Settings)) {
+	configureMu.Lock()
+	defer configureMu.Unlock()
+	s := *currentSettings()
+	configure(&s)
+	settings.Store(&s)
+	instrumenter.InvalidateInstrumenters(instrumentationScope)
+}
+
+// currentSettings returns the settings set with Configure. Callers read it
+// once per build or request so that they see consistent settings.
+func currentSettings() *Settings {
+	if s := settings.Load(); s != nil {
+		return s
+	}
+	return &defaultSettings
+}
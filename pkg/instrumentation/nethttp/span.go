@@ -0,0 +1,29 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+)
+
+// SpanFromHookContext returns the span started by the server or client hook
+// of ictx, so that hooks can enrich it with custom attributes. For other hooks
+// it is the span of the context.Context parameter of the hooked function, if
+// any. A no-op span is returned when no span is active.
+func SpanFromHookContext(ictx inst.HookContext) trace.Span {
+	if state, ok := ictx.GetKeyData(serverStateKey).(*serverState); ok {
+		return trace.SpanFromContext(state.ctx)
+	}
+	if state, ok := ictx.GetKeyData(clientStateKey).(*clientState); ok {
+		return trace.SpanFromContext(state.ctx)
+	}
+	if ctx, ok := ictx.Context().(context.Context); ok {
+		return trace.SpanFromContext(ctx)
+	}
+	return trace.SpanFromContext(context.Background())
+}
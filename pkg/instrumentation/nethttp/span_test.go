@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst/insttest"
+)
+
+func TestSpanFromHookContext(t *testing.T) {
+	sr := useTracerProvider(t)
+	customKey := attribute.Key("app.tenant")
+
+	w, r := httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)
+	ictx := &insttest.HookContext{Params: []interface{}{nil, w, r}}
+	BeforeServeHTTP(ictx, nil, w, r)
+	span := SpanFromHookContext(ictx)
+	require.True(t, span.SpanContext().IsValid())
+	span.SetAttributes(customKey.String("acme"))
+	AfterServeHTTP(ictx)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "acme", spanAttrs(spans[0])[customKey].AsString())
+}
+
+func TestSpanFromHookContextOtherHooks(t *testing.T) {
+	useTracerProvider(t)
+
+	span := SpanFromHookContext(&insttest.HookContext{})
+	require.False(t, span.SpanContext().IsValid(), "a no-op span should be returned without an active span")
+	require.False(t, span.IsRecording())
+
+	w, r := httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)
+	ictx := &insttest.HookContext{Params: []interface{}{nil, w, r}}
+	BeforeServeHTTP(ictx, nil, w, r)
+	ctx := ictx.GetParam(2).(*http.Request).Context()
+	span = SpanFromHookContext(&insttest.HookContext{Ctx: ctx})
+	require.Equal(t, trace.SpanContextFromContext(ctx), span.SpanContext(),
+		"the span of the context.Context parameter should be returned")
+	AfterServeHTTP(ictx)
+}
@@ -0,0 +1,154 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/utils"
+)
+
+const (
+	requestSpanName = "HTTP request"
+	retryEventName  = "retry"
+	// retryAttemptKey is the number of the attempt, 1 for the first retry
+	retryAttemptKey = attribute.Key("retry.attempt")
+	// retryBackoffKey is the time in seconds between the end of the previous
+	// attempt and the start of the retry
+	retryBackoffKey = attribute.Key("retry.backoff")
+)
+
+type attemptsKey struct{}
+
+// attempts remembers the outcome of the last attempt of a request started
+// with StartRequest, which the next attempt reports as the retry reason
+type attempts struct {
+	mu         sync.Mutex
+	count      int
+	statusCode int
+	err        error
+	endTime    time.Time
+}
+
+// startAttempt counts an attempt and returns the attributes of its retry
+// event, or nil for the first attempt
+func (a *attempts) startAttempt(now time.Time) []attribute.KeyValue {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.count++
+	if a.count == 1 {
+		return nil
+	}
+	attrs := []attribute.KeyValue{
+		retryAttemptKey.Int(a.count - 1),
+		retryBackoffKey.Float64(now.Sub(a.endTime).Seconds()),
+	}
+	if a.statusCode > 0 {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(a.statusCode))
+	}
+	switch {
+	case a.err != nil:
+		attrs = append(attrs, semconv.ErrorTypeKey.String(errorType(a.err)))
+	case a.statusCode >= http.StatusBadRequest:
+		attrs = append(attrs, semconv.ErrorTypeKey.String(strconv.Itoa(a.statusCode)))
+	}
+	return attrs
+}
+
+func (a *attempts) endAttempt(resp *http.Response, err error, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.statusCode = 0
+	if resp != nil {
+		a.statusCode = resp.StatusCode
+	}
+	a.err = err
+	a.endTime = now
+}
+
+// Transport is an http.RoundTripper recording a client span for every round
+// trip of the wrapped transport. It is meant for applications that are not
+// built with compile-time instrumentation.
+type Transport struct {
+	// Base is the wrapped transport, http.DefaultTransport when nil
+	Base http.RoundTripper
+	// SpanPerAttempt records the attempts of a request started with
+	// StartRequest as child spans of a parent "HTTP request" span, each tagged
+	// with its http.request.resend_count. Every retry has a "retry" event with
+	// the outcome of the previous attempt and the backoff since it ended.
+	// Every round trip is a single span otherwise.
+	SpanPerAttempt bool
+}
+
+// NewTransport wraps base, which may be nil for http.DefaultTransport.
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+// StartRequest starts the parent span of the attempts sent with the returned
+// context when SpanPerAttempt is set, and returns ctx as is otherwise. The
+// returned function ends the span once the request succeeded or was given up.
+func (t *Transport) StartRequest(ctx context.Context) (context.Context, func()) {
+	if !t.SpanPerAttempt {
+		return ctx, func() {}
+	}
+	ctx, span := tracer().Start(ctx, requestSpanName)
+	// The first attempt increments the counter to a resend count of 0
+	resendCount := int32(-1)
+	ctx = context.WithValue(ctx, utils.ClientResendKey, &resendCount)
+	ctx = context.WithValue(ctx, attemptsKey{}, &attempts{})
+	return ctx, func() { span.End() }
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	parentCtx := r.Context()
+	if isExcluded(parentCtx) {
+		return base.RoundTrip(r)
+	}
+	retries, _ := parentCtx.Value(attemptsKey{}).(*attempts)
+	if !t.SpanPerAttempt {
+		// Attempts only count towards the request started in per attempt mode
+		parentCtx = context.WithValue(parentCtx, utils.ClientResendKey, nil)
+		retries = nil
+	}
+	// RoundTrippers must not modify the request, clone it before injecting
+	// the span context into its headers
+	request := HTTPClientRequest{Request: r.Clone(r.Context())}
+	// Looked up for every round trip so that changed settings apply
+	clientInstrumenter := BuildClientInstrumenter()
+	ctx := clientInstrumenter.Start(parentCtx, request)
+	if retries != nil {
+		// The retry event explains why the attempt was sent
+		if attrs := retries.startAttempt(time.Now()); attrs != nil {
+			trace.SpanFromContext(ctx).AddEvent(retryEventName, trace.WithAttributes(attrs...))
+		}
+	}
+	// Wrapped transports must not record the round trip again
+	request.Request = request.Request.WithContext(withClientSpan(ctx))
+	resp, err := base.RoundTrip(request.Request)
+	if retries != nil {
+		retries.endAttempt(resp, err, time.Now())
+	}
+	clientInstrumenter.End(ctx, instrumenter.Invocation[HTTPClientRequest, HTTPClientResponse]{
+		Request:  request,
+		Response: HTTPClientResponse{Response: resp},
+		Err:      err,
+	})
+	return resp, err
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
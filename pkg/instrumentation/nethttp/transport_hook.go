@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+const clientStateKey = "nethttp.client"
+
+// clientSpanKey marks the context of requests sent within a client span, the
+// transports they go through do not record nested client spans
+type clientSpanKey struct{}
+
+func withClientSpan(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clientSpanKey{}, true)
+}
+
+func inClientSpan(ctx context.Context) bool {
+	return ctx.Value(clientSpanKey{}) != nil
+}
+
+// excludedClientKey marks the context of requests sent by excluded clients
+type excludedClientKey struct{}
+
+func isExcluded(ctx context.Context) bool {
+	return ctx.Value(excludedClientKey{}) != nil
+}
+
+// ExcludeClient excludes the requests sent by c from the client
+// instrumentation, both compile-time and the library Transport, e.g. for the
+// client of a telemetry exporter. It wraps the transport of c and must be
+// called before c is used.
+func ExcludeClient(c *http.Client) {
+	if _, ok := c.Transport.(excludedTransport); ok {
+		return
+	}
+	c.Transport = excludedTransport{base: c.Transport}
+}
+
+// excludedTransport marks the requests of an excluded client before they
+// reach its transport
+type excludedTransport struct {
+	base http.RoundTripper
+}
+
+func (t excludedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(r.WithContext(context.WithValue(r.Context(), excludedClientKey{}, true)))
+}
+
+// clientState is carried from BeforeRoundTrip to AfterRoundTrip
+type clientState struct {
+	ctx          context.Context
+	request      HTTPClientRequest
+	instrumenter *instrumenter.PropagatingToDownstreamInstrumenter[HTTPClientRequest, HTTPClientResponse]
+}
+
+// BeforeRoundTrip starts the client span of a request sent by http.Transport,
+// including through custom transports wrapping it. Requests already within a
+// client span, e.g. sent through the library Transport, or by a client excluded
+// with ExcludeClient are left untouched.
+func BeforeRoundTrip(ictx inst.HookContext, _ *http.Transport, r *http.Request) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseBefore)
+	if !instrumenter.RuleEnabled(ictx.GetRuleName()) || inClientSpan(r.Context()) || isExcluded(r.Context()) {
+		return
+	}
+	clientInstrumenter := BuildClientInstrumenter()
+	// RoundTrippers must not modify the request, clone it before injecting
+	// the span context into its headers
+	request := HTTPClientRequest{Request: r.Clone(r.Context())}
+	ctx := clientInstrumenter.Start(r.Context(), request)
+	request.Request = request.Request.WithContext(withClientSpan(ctx))
+	ictx.SetParam(1, request.Request)
+	state := &clientState{ctx: ctx, request: request, instrumenter: clientInstrumenter}
+	endIfIncomplete(ctx, state)
+	ictx.SetKeyData(clientStateKey, state)
+}
+
+func AfterRoundTrip(ictx inst.HookContext, resp *http.Response, err error) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseAfter)
+	state, ok := ictx.GetKeyData(clientStateKey).(*clientState)
+	if !ok {
+		return
+	}
+	completed(state)
+	state.instrumenter.End(state.ctx, instrumenter.Invocation[HTTPClientRequest, HTTPClientResponse]{
+		Request:  state.request,
+		Response: HTTPClientResponse{Response: resp},
+		Err:      err,
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst/insttest"
+)
+
+// hookedTransport mimics the trampoline of the client hook around
+// (*http.Transport).RoundTrip
+type hookedTransport struct {
+	base *http.Transport
+	rule string
+}
+
+func (t hookedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ictx := &insttest.HookContext{Params: []interface{}{t.base, r}, RuleName: t.rule}
+	BeforeRoundTrip(ictx, t.base, r)
+	resp, err := t.base.RoundTrip(ictx.GetParam(1).(*http.Request))
+	AfterRoundTrip(ictx, resp, err)
+	return resp, err
+}
+
+func TestRoundTripHooks(t *testing.T) {
+	sr := useTracerProvider(t)
+	originalPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(originalPropagator)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	client := &http.Client{Transport: hookedTransport{base: &http.Transport{}}}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	require.Contains(t, spans[0].Attributes(), semconv.HTTPResponseStatusCode(http.StatusAccepted))
+	require.Contains(t, traceparent, spans[0].SpanContext().SpanID().String(),
+		"the span context should be propagated to the server")
+	require.Empty(t, req.Header.Get("traceparent"), "the request of the caller should be left untouched")
+}
+
+func TestHooksRuleDisabled(t *testing.T) {
+	sr := useTracerProvider(t)
+	t.Setenv("OTEL_INSTRUMENTATION_SERVER_HOOK_ENABLED", "false")
+
+	// The server mimics the trampoline of server_hook around its handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ictx := &insttest.HookContext{Params: []interface{}{nil, w, r}, RuleName: "server_hook"}
+		BeforeServeHTTP(ictx, nil, w, r)
+		defer AfterServeHTTP(ictx)
+		ictx.GetParam(1).(http.ResponseWriter).WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: hookedTransport{base: &http.Transport{}, rule: "client_hook"}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1, "only client_hook should record a span")
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	require.Contains(t, spans[0].Attributes(), semconv.HTTPResponseStatusCode(http.StatusAccepted))
+}
+
+func TestRoundTripHooksExcludedClient(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	instrumented := &http.Client{Transport: hookedTransport{base: &http.Transport{}}}
+	excluded := &http.Client{Transport: hookedTransport{base: &http.Transport{}}}
+	ExcludeClient(excluded)
+	ExcludeClient(excluded)
+	for _, client := range []*http.Client{instrumented, excluded} {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	require.Len(t, sr.Ended(), 1, "only the requests of the instrumented client should be recorded")
+
+	// The library transport honors the exclusion as well
+	libraryClient := &http.Client{Transport: NewTransport(nil)}
+	ExcludeClient(libraryClient)
+	resp, err := libraryClient.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Len(t, sr.Ended(), 1)
+}
+
+func TestRoundTripHooksNested(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	// The library transport wrapping an instrumented http.Transport
+	client := &http.Client{Transport: NewTransport(hookedTransport{base: &http.Transport{}})}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Len(t, sr.Ended(), 1, "the round trip should only be recorded once")
+}
@@ -0,0 +1,478 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nethttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+	semconvhttp "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api-semconv/instrumenter/http"
+)
+
+// sendWithRetry sends GET requests to url until one succeeds, waiting for
+// backoff between attempts as a retrying client would
+func sendWithRetry(t *testing.T, transport *Transport, url string, backoff time.Duration) {
+	ctx, end := transport.StartRequest(context.Background())
+	defer end()
+	client := &http.Client{Transport: transport}
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return
+		}
+	}
+	t.Fatal("request did not succeed")
+}
+
+// failingHandler fails the first failures requests and serves the following
+// ones
+func failingHandler(failures int32) http.Handler {
+	var requests atomic.Int32
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func TestTransportSpanPerAttempt(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	server := httptest.NewServer(failingHandler(1))
+	defer server.Close()
+	transport := NewTransport(nil)
+	transport.SpanPerAttempt = true
+	sendWithRetry(t, transport, server.URL, 0)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 3)
+	parent := spans[2]
+	require.Equal(t, requestSpanName, parent.Name())
+	attempts := spans[:2]
+	for _, attempt := range attempts {
+		require.Equal(t, trace.SpanKindClient, attempt.SpanKind())
+		require.Equal(t, parent.SpanContext().SpanID(), attempt.Parent().SpanID())
+	}
+
+	first := spanAttrs(attempts[0])
+	_, ok := first[semconv.HTTPRequestResendCountKey]
+	require.False(t, ok, "the first attempt is not a resend")
+	require.Equal(t, int64(http.StatusServiceUnavailable), first[semconv.HTTPResponseStatusCodeKey].AsInt64())
+	require.Equal(t, "503", first[semconv.ErrorTypeKey].AsString())
+
+	second := spanAttrs(attempts[1])
+	require.Equal(t, int64(1), second[semconv.HTTPRequestResendCountKey].AsInt64())
+	require.Equal(t, int64(http.StatusOK), second[semconv.HTTPResponseStatusCodeKey].AsInt64())
+}
+
+func TestTransportRetryEvents(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	server := httptest.NewServer(failingHandler(2))
+	defer server.Close()
+	transport := NewTransport(nil)
+	transport.SpanPerAttempt = true
+	const backoff = 20 * time.Millisecond
+	sendWithRetry(t, transport, server.URL, backoff)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 4)
+	require.Empty(t, spans[0].Events(), "the first attempt is not a retry")
+	for i, attempt := range spans[1:3] {
+		events := attempt.Events()
+		require.Len(t, events, 1)
+		require.Equal(t, retryEventName, events[0].Name)
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, attr := range events[0].Attributes {
+			attrs[attr.Key] = attr.Value
+		}
+		require.Equal(t, int64(i+1), attrs[retryAttemptKey].AsInt64())
+		require.Equal(t, int64(http.StatusServiceUnavailable), attrs[semconv.HTTPResponseStatusCodeKey].AsInt64())
+		require.Equal(t, "503", attrs[semconv.ErrorTypeKey].AsString())
+		require.GreaterOrEqual(t, attrs[retryBackoffKey].AsFloat64(), backoff.Seconds())
+	}
+}
+
+func TestTransportSpanPerRoundTrip(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	originalPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(originalPropagator)
+	sendWithRetry(t, NewTransport(nil), server.URL, 0)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, trace.SpanKindClient, span.SpanKind())
+	require.False(t, span.Parent().IsValid())
+	_, ok := spanAttrs(span)[semconv.HTTPRequestResendCountKey]
+	require.False(t, ok)
+	require.Contains(t, traceparent, span.SpanContext().SpanID().String(),
+		"the span context should be propagated to the server")
+}
+
+func TestTransportConnectionRefused(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	// Nothing listens on the address of a closed server
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	client := &http.Client{Transport: NewTransport(nil)}
+	resp, err := client.Get(url)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	require.Error(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, trace.SpanKindClient, span.SpanKind())
+	require.Equal(t, codes.Error, span.Status().Code)
+	attrs := spanAttrs(span)
+	require.Equal(t, "*net.OpError", attrs[semconv.ErrorTypeKey].AsString())
+	_, ok := attrs[semconv.HTTPResponseStatusCodeKey]
+	require.False(t, ok, "no response was received")
+}
+
+func TestBuildClientInstrumenterCached(t *testing.T) {
+	useTracerProvider(t)
+
+	// Transports share the client instrumenter rather than building their own
+	cached := BuildClientInstrumenter()
+	require.Same(t, cached, BuildClientInstrumenter())
+
+	// Changed settings rebuild it
+	configure(t, func(s *Settings) { s.CapturedResponseHeaders = []string{"Content-Encoding"} })
+	require.NotSame(t, cached, BuildClientInstrumenter())
+}
+
+// roundTripperFunc adapts a function to an http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTransportGzipBodySize(t *testing.T) {
+	configure(t, func(s *Settings) { s.CapturedResponseHeaders = []string{"Content-Encoding"} })
+
+	body := bytes.Repeat([]byte("hello "), 100)
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, err := zw.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Length", strconv.Itoa(compressed.Len()))
+		_, _ = w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+	contentEncodingKey := attribute.Key("http.response.header.content-encoding")
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		base           http.RoundTripper
+		// wantSize is the expected http.response.body.size, -1 when absent
+		wantSize int64
+		// wantUncompressed is the expected
+		// http.response.body.uncompressed_size, -1 when absent
+		wantUncompressed int64
+		wantEncoding     bool
+	}{
+		{
+			// The client decodes the body itself, the wire size is reported
+			name:             "encoded",
+			acceptEncoding:   "gzip",
+			wantSize:         int64(compressed.Len()),
+			wantUncompressed: -1,
+			wantEncoding:     true,
+		},
+		{
+			// http.Transport decodes the body as it is read and drops the
+			// Content-Length and Content-Encoding headers, no size is known
+			name:             "decoded by http.Transport",
+			wantSize:         -1,
+			wantUncompressed: -1,
+		},
+		{
+			name: "decoded with a known size",
+			base: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Header:        http.Header{},
+					Body:          io.NopCloser(bytes.NewReader(body)),
+					ContentLength: int64(len(body)),
+					Uncompressed:  true,
+				}, nil
+			}),
+			wantSize:         -1,
+			wantUncompressed: int64(len(body)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := useTracerProvider(t)
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			client := &http.Client{Transport: NewTransport(tt.base)}
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			_, err = io.Copy(io.Discard, resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			attrs := spanAttrs(spans[0])
+			size, ok := attrs[semconv.HTTPResponseBodySizeKey]
+			require.Equal(t, tt.wantSize >= 0, ok)
+			if ok {
+				require.Equal(t, tt.wantSize, size.AsInt64())
+			}
+			uncompressed, ok := attrs[semconvhttp.HTTPResponseBodyUncompressedSizeKey]
+			require.Equal(t, tt.wantUncompressed >= 0, ok)
+			if ok {
+				require.Equal(t, tt.wantUncompressed, uncompressed.AsInt64())
+			}
+			encoding, ok := attrs[contentEncodingKey]
+			require.Equal(t, tt.wantEncoding, ok)
+			if ok {
+				require.Equal(t, []string{"gzip"}, encoding.AsStringSlice())
+			}
+		})
+	}
+}
+
+func TestTransportContentType(t *testing.T) {
+	configure(t, func(s *Settings) { s.CapturedResponseHeaders = []string{"Content-Type"} })
+	sr := useTracerProvider(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewTransport(nil)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	attrs := spanAttrs(spans[0])
+	require.Equal(t, "application/json", attrs[semconvhttp.HTTPResponseContentTypeKey].AsString())
+	require.Equal(t, []string{"application/json; charset=utf-8"},
+		attrs[attribute.Key("http.response.header.content-type")].AsStringSlice())
+}
+
+func TestTransportNegotiatedProtocol(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+	plainServer := httptest.NewServer(handler)
+	defer plainServer.Close()
+
+	for _, tt := range []struct {
+		name     string
+		server   *httptest.Server
+		expected string
+	}{
+		{name: "h2", server: tlsServer, expected: "h2"},
+		{name: "plaintext", server: plainServer},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := useTracerProvider(t)
+
+			client := &http.Client{Transport: NewTransport(tt.server.Client().Transport)}
+			resp, err := client.Get(tt.server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			protocol, ok := spanAttrs(spans[0])[semconv.TLSNextProtocolKey]
+			if tt.expected == "" {
+				require.False(t, ok, "plaintext responses should not record a protocol")
+				return
+			}
+			require.Equal(t, tt.expected, protocol.AsString())
+		})
+	}
+}
+
+func TestSpanSchemaURL(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	server := httptest.NewServer(NewHandlerMiddleware()(http.NotFoundHandler()))
+	defer server.Close()
+	transport := NewTransport(nil)
+	transport.SpanPerAttempt = true
+	ctx, end := transport.StartRequest(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	end()
+
+	// The server, client and request spans
+	spans := sr.Ended()
+	require.Len(t, spans, 3)
+	for _, span := range spans {
+		scope := span.InstrumentationScope()
+		require.Equal(t, instrumentationName, scope.Name, span.Name())
+		require.Equal(t, semconv.SchemaURL, scope.SchemaURL, span.Name())
+	}
+}
+
+func TestTransportSpanName(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	for _, withServerAddress := range []bool{false, true} {
+		t.Run(strconv.FormatBool(withServerAddress), func(t *testing.T) {
+			configure(t, func(s *Settings) { s.ClientSpanNameWithServerAddress = withServerAddress })
+			sr := useTracerProvider(t)
+
+			client := &http.Client{Transport: NewTransport(nil)}
+			resp, err := client.Get(server.URL + "/users/42")
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			want := http.MethodGet
+			if withServerAddress {
+				// The host without the port nor the path
+				want = "GET 127.0.0.1"
+			}
+			require.Equal(t, want, spans[0].Name())
+		})
+	}
+}
+
+func TestTransportMetricAttributes(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	originalMP := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(originalMP)
+	useTracerProvider(t)
+
+	// Requests to a well-known port, served by a local server
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	base := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+	client := &http.Client{Transport: NewTransport(base)}
+	for _, path := range []string{"/users/1", "/users/2"} {
+		resp, err := client.Get("http://example.com" + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	rm := &metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(context.Background(), rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	duration := rm.ScopeMetrics[0].Metrics[0]
+	require.Equal(t, "http.client.request.duration", duration.Name)
+	hist, ok := duration.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	// Both paths share the data point of the upstream
+	require.Len(t, hist.DataPoints, 1)
+	require.Equal(t, uint64(2), hist.DataPoints[0].Count)
+	attrs := hist.DataPoints[0].Attributes
+	address, ok := attrs.Value(semconv.ServerAddressKey)
+	require.True(t, ok)
+	require.Equal(t, "example.com", address.AsString())
+	port, ok := attrs.Value(semconv.ServerPortKey)
+	require.True(t, ok)
+	require.Equal(t, int64(80), port.AsInt64())
+	require.False(t, attrs.HasValue(semconv.URLPathKey))
+}
+
+func TestInstrumentersRequestWithoutURL(t *testing.T) {
+	sr := useTracerProvider(t)
+
+	resp, err := NewTransport(nil).RoundTrip(&http.Request{})
+	if resp != nil {
+		resp.Body.Close()
+	}
+	require.Error(t, err, "the base transport should reject the request")
+
+	serverInstrumenter := BuildServerInstrumenter()
+	request := HTTPServerRequest{Request: &http.Request{}}
+	ctx := serverInstrumenter.Start(context.Background(), request)
+	serverInstrumenter.End(ctx, instrumenter.Invocation[HTTPServerRequest, HTTPServerResponse]{
+		Request:  request,
+		Response: HTTPServerResponse{StatusCode: http.StatusOK},
+	})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		attrs := spanAttrs(span)
+		require.Empty(t, attrs[semconv.ServerAddressKey].AsString())
+		require.Empty(t, attrs[semconv.URLPathKey].AsString())
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst"
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+var redisInstrumenter = BuildRedisInstrumenter()
+
+// redisCmd is the subset of go-redis Cmder used by the hooks, declared here
+// to avoid depending on the client library
+type redisCmd interface {
+	Name() string
+	Args() []interface{}
+}
+
+type redisData struct {
+	ctx     context.Context
+	request RedisRequest
+}
+
+// BeforeProcess is called before (*baseClient).process dispatches the command
+func BeforeProcess(ictx inst.HookContext, client interface{}, ctx context.Context, cmd interface{}) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseBefore)
+	c, ok := cmd.(redisCmd)
+	if !ok || !instrumenter.RuleEnabled(ictx.GetRuleName()) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	request := RedisRequest{Args: c.Args()}
+	if len(request.Args) == 0 {
+		request.Args = []interface{}{c.Name()}
+	}
+	newCtx := redisInstrumenter.Start(instrumenter.ContextWithRuleName(ctx, ictx.GetRuleName()), request)
+	ictx.SetParam(1, newCtx)
+	ictx.SetData(&redisData{ctx: newCtx, request: request})
+}
+
+func AfterProcess(ictx inst.HookContext, err error) {
+	instrumenter.RecordHookInvocation(ictx.GetRuleName(), inst.PhaseAfter)
+	data, ok := ictx.GetData().(*redisData)
+	if !ok || data == nil {
+		return
+	}
+	redisInstrumenter.End(data.ctx, instrumenter.Invocation[RedisRequest, RedisResponse]{
+		Request:  data.request,
+		Response: RedisResponse{},
+		Err:      err,
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst/insttest"
+)
+
+type fakeCmd struct {
+	args []interface{}
+}
+
+func (c fakeCmd) Name() string        { return c.args[0].(string) }
+func (c fakeCmd) Args() []interface{} { return c.args }
+
+// dispatch simulates the instrumented (*baseClient).process call
+func dispatch(cmd fakeCmd) {
+	ictx := &insttest.HookContext{Params: make([]interface{}, 3)}
+	BeforeProcess(ictx, nil, context.Background(), cmd)
+	ctx, _ := ictx.GetParam(1).(context.Context)
+	if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		panic("span context should be propagated to the command")
+	}
+	AfterProcess(ictx, nil)
+}
+
+// The package instrumenter holds a global tracer, which only delegates to the
+// first provider registered, so all tests share one recorder.
+var sr = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	os.Exit(m.Run())
+}
+
+// endedSince returns the spans ended after the first n ones
+func endedSince(n int) []sdktrace.ReadOnlySpan {
+	return sr.Ended()[n:]
+}
+
+func TestProcessOmitsArgsByDefault(t *testing.T) {
+	n := len(sr.Ended())
+	dispatch(fakeCmd{args: []interface{}{"set", "key", "secret"}})
+
+	spans := endedSince(n)
+	require.Len(t, spans, 1)
+	assert.Equal(t, "SET", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	system, _ := attrs.Value(semconv.DBSystemKey)
+	assert.Equal(t, "redis", system.AsString())
+	op, _ := attrs.Value(semconv.DBOperationKey)
+	assert.Equal(t, "SET", op.AsString())
+	assert.False(t, attrs.HasValue(semconv.DBStatementKey))
+}
+
+func TestProcessCapturesFirstArgs(t *testing.T) {
+	n := len(sr.Ended())
+	CapturedArgs = 1
+	defer func() { CapturedArgs = 0 }()
+	dispatch(fakeCmd{args: []interface{}{"set", "key", "secret"}})
+
+	spans := endedSince(n)
+	require.Len(t, spans, 1)
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	text, _ := attrs.Value(semconv.DBStatementKey)
+	assert.Equal(t, "SET key ?", text.AsString())
+}
+
+func TestBeforeProcessIgnoresUnknownCmd(t *testing.T) {
+	n := len(sr.Ended())
+	ictx := &insttest.HookContext{Params: make([]interface{}, 3)}
+	BeforeProcess(ictx, nil, context.Background(), "not a command")
+	AfterProcess(ictx, nil)
+	assert.Empty(t, endedSince(n))
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	instrumenter "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/inst-api"
+)
+
+const (
+	instrumentationName = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation/pkg/instrumentation/redis"
+	// envCaptureArgs is the number of leading command arguments recorded in
+	// db.statement, arguments are omitted by default
+	envCaptureArgs = "OTEL_INSTRUMENTATION_REDIS_CAPTURE_ARGS"
+)
+
+// CapturedArgs is the number of command arguments, after the command name,
+// recorded as part of db.statement. Arguments may carry sensitive data so
+// none are recorded by default.
+var CapturedArgs = capturedArgsFromEnv()
+
+func capturedArgsFromEnv() int {
+	n, err := strconv.Atoi(os.Getenv(envCaptureArgs))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// RedisRequest describes a single command sent by the client, Args includes
+// the command name as its first element.
+type RedisRequest struct {
+	Args []interface{}
+}
+
+type RedisResponse struct{}
+
+type redisSpanNameExtractor struct{}
+
+func (r redisSpanNameExtractor) Extract(request RedisRequest) string {
+	if op := operationName(request); op != "" {
+		return op
+	}
+	return "redis"
+}
+
+type redisAttrsExtractor struct{}
+
+func (r redisAttrsExtractor) OnStart(parentContext context.Context, attributes []attribute.KeyValue,
+	request RedisRequest,
+) ([]attribute.KeyValue, context.Context) {
+	attributes = append(attributes, semconv.DBSystemRedis)
+	if op := operationName(request); op != "" {
+		attributes = append(attributes, semconv.DBOperation(op))
+	}
+	if CapturedArgs > 0 && len(request.Args) > 0 {
+		attributes = append(attributes, semconv.DBStatement(queryText(request.Args, CapturedArgs)))
+	}
+	return attributes, parentContext
+}
+
+func (r redisAttrsExtractor) OnEnd(context context.Context, attributes []attribute.KeyValue,
+	request RedisRequest, response RedisResponse, err error,
+) ([]attribute.KeyValue, context.Context) {
+	return attributes, context
+}
+
+func operationName(request RedisRequest) string {
+	if len(request.Args) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fmt.Sprint(request.Args[0]))
+}
+
+// queryText renders the command name followed by at most n arguments, the
+// remaining arguments are replaced with "?".
+func queryText(args []interface{}, n int) string {
+	parts := make([]string, 0, len(args))
+	parts = append(parts, strings.ToUpper(fmt.Sprint(args[0])))
+	for i, arg := range args[1:] {
+		if i < n {
+			parts = append(parts, fmt.Sprint(arg))
+		} else {
+			parts = append(parts, "?")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func BuildRedisInstrumenter() instrumenter.Instrumenter[RedisRequest, RedisResponse] {
+	builder := &instrumenter.Builder[RedisRequest, RedisResponse]{}
+	return builder.Init().SetSpanNameExtractor(redisSpanNameExtractor{}).
+		SetSpanKindExtractor(&instrumenter.AlwaysClientExtractor[RedisRequest]{}).
+		AddAttributesExtractor(redisAttrsExtractor{}).
+		SetInstrumentEnabler(instrumenter.NewEnvInstrumentEnabler("redis")).
+		SetInstrumentationScope(instrumentation.Scope{
+			Name:      instrumentationName,
+			Version:   "0.0.1",
+			SchemaURL: semconv.SchemaURL,
+		}).BuildInstrumenter()
+}
@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsetup
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	envMetricExportInterval     = "OTEL_METRIC_EXPORT_INTERVAL"
+	defaultMetricExportInterval = 60 * time.Second
+	envOTLPHeaders              = "OTEL_EXPORTER_OTLP_HEADERS"
+	envBSPExportTimeout         = "OTEL_BSP_EXPORT_TIMEOUT"
+	defaultExportTimeout        = 30 * time.Second
+	envExemplarFilter           = "OTEL_METRICS_EXEMPLAR_FILTER"
+)
+
+// Config configures the pipelines of the OpenTelemetry SDK.
+type Config struct {
+	// MetricExportInterval is the time between two exports of the periodic
+	// metric reader. OTEL_METRIC_EXPORT_INTERVAL, in milliseconds, is used when
+	// zero, and 60s when neither is set.
+	MetricExportInterval time.Duration
+	// OTLPHeaders are sent with every export of the OTLP trace and metric
+	// exporters, e.g. the Authorization header of a vendor backend. They take
+	// precedence over the headers of the same key in OTEL_EXPORTER_OTLP_HEADERS.
+	OTLPHeaders map[string]string
+	// ExportTimeout bounds every export of the span processor and the metric
+	// reader, retries included. When zero, OTEL_BSP_EXPORT_TIMEOUT, in
+	// milliseconds, is used by the span processor and 30s when it is not set,
+	// the metric reader keeps the timeout of the SDK.
+	ExportTimeout time.Duration
+	// ExportRetry retries the failed exports within the ExportTimeout
+	ExportRetry RetryConfig
+	// SyncExport exports every span as soon as it ends with a simple span
+	// processor instead of batching them, so that tests and development
+	// environments see the spans without waiting. Exports then block the end
+	// of spans and are not bounded by ExportTimeout, do not use it in
+	// production.
+	SyncExport bool
+	// ExemplarFilter selects the measurements recorded as exemplars, e.g.
+	// exemplar.AlwaysOnFilter. OTEL_METRICS_EXEMPLAR_FILTER, one of
+	// always_on, always_off and trace_based, is used when nil, and
+	// exemplar.TraceBasedFilter when neither is set so that exemplars link to
+	// sampled spans only.
+	ExemplarFilter exemplar.Filter
+}
+
+func (c Config) metricExportInterval() time.Duration {
+	if c.MetricExportInterval > 0 {
+		return c.MetricExportInterval
+	}
+	if ms, err := strconv.Atoi(os.Getenv(envMetricExportInterval)); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return defaultMetricExportInterval
+}
+
+func (c Config) exportTimeout() time.Duration {
+	if c.ExportTimeout > 0 {
+		return c.ExportTimeout
+	}
+	if ms, err := strconv.Atoi(os.Getenv(envBSPExportTimeout)); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return defaultExportTimeout
+}
+
+func (c Config) exemplarFilter() exemplar.Filter {
+	if c.ExemplarFilter != nil {
+		return c.ExemplarFilter
+	}
+	switch os.Getenv(envExemplarFilter) {
+	case "always_on":
+		return exemplar.AlwaysOnFilter
+	case "always_off":
+		return exemplar.AlwaysOffFilter
+	default:
+		return exemplar.TraceBasedFilter
+	}
+}
+
+// MetricExporterFactory creates the metric exporter sending the given headers
+// with every export, e.g. with otlpmetrichttp.WithHeaders.
+type MetricExporterFactory func(headers map[string]string) (sdkmetric.Exporter, error)
+
+// SpanExporterFactory creates the span exporter sending the given headers
+// with every export, e.g. with otlptracehttp.WithHeaders.
+type SpanExporterFactory func(headers map[string]string) (sdktrace.SpanExporter, error)
+
+// MeterProviderOptions returns the options of a MeterProvider exporting the
+// metrics with NewMetricReader to the exporter created with the OTLPHeaders of
+// cfg, and recording the exemplars selected by the configured filter.
+func MeterProviderOptions(cfg Config, newExporter MetricExporterFactory) ([]sdkmetric.Option, error) {
+	exporter, err := newExporter(OTLPHeaders(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return []sdkmetric.Option{
+		sdkmetric.WithReader(NewMetricReader(cfg, exporter)),
+		sdkmetric.WithExemplarFilter(cfg.exemplarFilter()),
+	}, nil
+}
+
+// TracerProviderOptions returns the options of a TracerProvider exporting the
+// spans with NewSpanProcessor to the exporter created with the OTLPHeaders of
+// cfg.
+func TracerProviderOptions(cfg Config, newExporter SpanExporterFactory) ([]sdktrace.TracerProviderOption, error) {
+	exporter, err := newExporter(OTLPHeaders(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return []sdktrace.TracerProviderOption{
+		sdktrace.WithSpanProcessor(NewSpanProcessor(cfg, exporter)),
+	}, nil
+}
+
+// NewMetricReader returns the periodic reader pushing the metrics to exporter,
+// e.g. an OTLP metric exporter, at the configured interval.
+func NewMetricReader(cfg Config, exporter sdkmetric.Exporter) sdkmetric.Reader {
+	opts := []sdkmetric.PeriodicReaderOption{sdkmetric.WithInterval(cfg.metricExportInterval())}
+	if cfg.ExportTimeout > 0 {
+		opts = append(opts, sdkmetric.WithTimeout(cfg.ExportTimeout))
+	}
+	return sdkmetric.NewPeriodicReader(cfg.ExportRetry.metricExporter(exporter), opts...)
+}
+
+// NewSpanProcessor returns the batch processor exporting the spans to
+// exporter, e.g. an OTLP trace exporter, within the configured timeout. It is
+// a simple span processor with SyncExport.
+func NewSpanProcessor(cfg Config, exporter sdktrace.SpanExporter) sdktrace.SpanProcessor {
+	exporter = cfg.ExportRetry.spanExporter(exporter)
+	if cfg.SyncExport {
+		return sdktrace.NewSimpleSpanProcessor(exporter)
+	}
+	return sdktrace.NewBatchSpanProcessor(exporter, sdktrace.WithExportTimeout(cfg.exportTimeout()))
+}
+
+// OTLPHeaders returns the headers passed to the exporter factories of
+// MeterProviderOptions and TracerProviderOptions. They are the comma-separated key=value pairs of
+// OTEL_EXPORTER_OTLP_HEADERS, with URL-encoded values, merged with the
+// OTLPHeaders of cfg.
+func OTLPHeaders(cfg Config) map[string]string {
+	headers := parseHeaders(os.Getenv(envOTLPHeaders))
+	for key, value := range cfg.OTLPHeaders {
+		headers[key] = value
+	}
+	return headers
+}
+
+// parseHeaders parses the key=value pairs of a W3C baggage-like list, invalid
+// pairs are skipped.
+func parseHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		key, err := url.PathUnescape(strings.TrimSpace(k))
+		if err != nil || key == "" {
+			continue
+		}
+		val, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		headers[key] = val
+	}
+	return headers
+}
@@ -0,0 +1,269 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsetup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// countingExporter counts the exports of the periodic reader
+type countingExporter struct {
+	exports atomic.Int32
+}
+
+func (*countingExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+func (*countingExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (e *countingExporter) Export(context.Context, *metricdata.ResourceMetrics) error {
+	e.exports.Add(1)
+	return nil
+}
+
+func (*countingExporter) ForceFlush(context.Context) error { return nil }
+
+func (*countingExporter) Shutdown(context.Context) error { return nil }
+
+// exemplarExporter keeps the number of exemplars of the last export
+type exemplarExporter struct {
+	countingExporter
+	exemplars atomic.Int32
+}
+
+func (e *exemplarExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	n := 0
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				for _, dp := range sum.DataPoints {
+					n += len(dp.Exemplars)
+				}
+			}
+		}
+	}
+	e.exemplars.Store(int32(n))
+	return nil
+}
+
+func TestMetricExportInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		env      string
+		expected time.Duration
+	}{
+		{name: "default", expected: defaultMetricExportInterval},
+		{name: "env", env: "5000", expected: 5 * time.Second},
+		{name: "invalid env", env: "5s", expected: defaultMetricExportInterval},
+		{name: "negative env", env: "-1", expected: defaultMetricExportInterval},
+		{
+			name:     "config over env",
+			config:   Config{MetricExportInterval: time.Second},
+			env:      "5000",
+			expected: time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envMetricExportInterval, tt.env)
+			assert.Equal(t, tt.expected, tt.config.metricExportInterval())
+		})
+	}
+}
+
+func TestMetricReaderExportsAtInterval(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		config Config
+		env    string
+	}{
+		{name: "config", config: Config{MetricExportInterval: 10 * time.Millisecond}},
+		{name: "env", env: "10"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envMetricExportInterval, tt.env)
+			exporter := &countingExporter{}
+			reader := NewMetricReader(tt.config, exporter)
+			mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+			defer func() { require.NoError(t, mp.Shutdown(context.Background())) }()
+
+			// Exports would only happen every minute with the default interval
+			require.Eventually(t, func() bool {
+				return exporter.exports.Load() >= 3
+			}, 5*time.Second, 5*time.Millisecond)
+		})
+	}
+}
+
+func TestOTLPHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		env      string
+		expected map[string]string
+	}{
+		{name: "none", expected: map[string]string{}},
+		{
+			name:     "env",
+			env:      " Authorization = Bearer%20token%3D ,x-tenant=acme",
+			expected: map[string]string{"Authorization": "Bearer token=", "x-tenant": "acme"},
+		},
+		{
+			name:     "invalid pairs",
+			env:      "novalue,=empty-key,bad=%zz,ok=1",
+			expected: map[string]string{"ok": "1"},
+		},
+		{
+			name:     "config",
+			config:   Config{OTLPHeaders: map[string]string{"Authorization": "Basic abc"}},
+			expected: map[string]string{"Authorization": "Basic abc"},
+		},
+		{
+			name:     "config over env",
+			config:   Config{OTLPHeaders: map[string]string{"x-tenant": "config"}},
+			env:      "x-tenant=env,x-region=eu",
+			expected: map[string]string{"x-tenant": "config", "x-region": "eu"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envOTLPHeaders, tt.env)
+			assert.Equal(t, tt.expected, OTLPHeaders(tt.config))
+		})
+	}
+}
+
+func TestProviderOptionsExporterHeaders(t *testing.T) {
+	t.Setenv(envOTLPHeaders, "x-tenant=env,x-region=eu")
+	cfg := Config{OTLPHeaders: map[string]string{"x-tenant": "config"}}
+	expected := map[string]string{"x-tenant": "config", "x-region": "eu"}
+
+	var metricHeaders, spanHeaders map[string]string
+	_, err := MeterProviderOptions(cfg, func(headers map[string]string) (sdkmetric.Exporter, error) {
+		metricHeaders = headers
+		return &countingExporter{}, nil
+	})
+	require.NoError(t, err)
+	_, err = TracerProviderOptions(cfg, func(headers map[string]string) (sdktrace.SpanExporter, error) {
+		spanHeaders = headers
+		return tracetest.NewInMemoryExporter(), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, metricHeaders)
+	assert.Equal(t, expected, spanHeaders)
+
+	errExporter := errors.New("exporter")
+	_, err = MeterProviderOptions(cfg, func(map[string]string) (sdkmetric.Exporter, error) {
+		return nil, errExporter
+	})
+	require.ErrorIs(t, err, errExporter)
+	_, err = TracerProviderOptions(cfg, func(map[string]string) (sdktrace.SpanExporter, error) {
+		return nil, errExporter
+	})
+	require.ErrorIs(t, err, errExporter)
+}
+
+func TestExportTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		env      string
+		expected time.Duration
+	}{
+		{name: "default", expected: defaultExportTimeout},
+		{name: "env", env: "5000", expected: 5 * time.Second},
+		{name: "invalid env", env: "5s", expected: defaultExportTimeout},
+		{
+			name:     "config over env",
+			config:   Config{ExportTimeout: time.Second},
+			env:      "5000",
+			expected: time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envBSPExportTimeout, tt.env)
+			assert.Equal(t, tt.expected, tt.config.exportTimeout())
+		})
+	}
+}
+
+func TestSpanProcessorSyncExport(t *testing.T) {
+	for _, sync := range []bool{false, true} {
+		exporter := tracetest.NewInMemoryExporter()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewSpanProcessor(Config{SyncExport: sync}, exporter)))
+		_, span := tp.Tracer("test").Start(context.Background(), "span")
+		span.End()
+
+		if sync {
+			require.Len(t, exporter.GetSpans(), 1, "the span should be exported when it ends")
+		} else {
+			require.Empty(t, exporter.GetSpans(), "the span should wait for the batch")
+		}
+		require.NoError(t, tp.ForceFlush(context.Background()))
+		require.Len(t, exporter.GetSpans(), 1)
+		require.NoError(t, tp.Shutdown(context.Background()))
+	}
+}
+
+func TestMeterProviderExemplarFilter(t *testing.T) {
+	sampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	tests := []struct {
+		name     string
+		config   Config
+		env      string
+		ctx      context.Context
+		expected int32
+	}{
+		{name: "default without span", ctx: context.Background(), expected: 0},
+		{name: "default with sampled span", ctx: sampled, expected: 1},
+		{name: "env", env: "always_on", ctx: context.Background(), expected: 1},
+		{
+			name:     "config over env",
+			config:   Config{ExemplarFilter: exemplar.AlwaysOffFilter},
+			env:      "always_on",
+			ctx:      sampled,
+			expected: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envExemplarFilter, tt.env)
+			exporter := &exemplarExporter{}
+			opts, err := MeterProviderOptions(tt.config, func(map[string]string) (sdkmetric.Exporter, error) {
+				return exporter, nil
+			})
+			require.NoError(t, err)
+			mp := sdkmetric.NewMeterProvider(opts...)
+			defer func() { require.NoError(t, mp.Shutdown(context.Background())) }()
+
+			counter, err := mp.Meter("test").Int64Counter("requests")
+			require.NoError(t, err)
+			counter.Add(tt.ctx, 1)
+			require.NoError(t, mp.ForceFlush(context.Background()))
+			assert.Equal(t, tt.expected, exporter.exemplars.Load())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package otelsetup provides building blocks to configure the OpenTelemetry
+// SDK used by the instrumented applications.
+package otelsetup
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type errorSampler struct {
+	base sdktrace.Sampler
+}
+
+// NewErrorSampler wraps base so that the spans it drops are recorded instead,
+// as the outcome of a span is only known once it ends. Together with
+// NewErrorSpanProcessor, a low base sampling rate can be used while every
+// span ending in error is still exported. Recording the dropped spans has a
+// cost, though much lower than exporting them.
+func NewErrorSampler(base sdktrace.Sampler) sdktrace.Sampler {
+	return errorSampler{base: base}
+}
+
+func (s errorSampler) ShouldSample(parameters sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.base.ShouldSample(parameters)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+func (s errorSampler) Description() string {
+	return "ErrorSampler{" + s.base.Description() + "}"
+}
+
+type errorSpanProcessor struct {
+	next sdktrace.SpanProcessor
+}
+
+// NewErrorSpanProcessor wraps next, typically a batch processor, so that it
+// also receives the spans left unsampled by the sampler of NewErrorSampler
+// when they end in error. Those spans are marked as sampled, processors and
+// exporters ignore unsampled spans otherwise.
+func NewErrorSpanProcessor(next sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+	return errorSpanProcessor{next: next}
+}
+
+func (p errorSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	// Whether an unsampled span is exported is only known once it ends
+	if s.SpanContext().IsSampled() {
+		p.next.OnStart(parent, s)
+	}
+}
+
+func (p errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	switch {
+	case s.SpanContext().IsSampled():
+		p.next.OnEnd(s)
+	case s.Status().Code == codes.Error:
+		p.next.OnEnd(sampledSpan{ReadOnlySpan: s})
+	}
+}
+
+func (p errorSpanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p errorSpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// sampledSpan is an ended span forced to be exported
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
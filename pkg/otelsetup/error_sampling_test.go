@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsetup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestErrorSpanExportedDespiteSampler(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewErrorSampler(sdktrace.TraceIDRatioBased(0))),
+		sdktrace.WithSpanProcessor(NewErrorSpanProcessor(sdktrace.NewSimpleSpanProcessor(exporter))),
+	)
+	defer func() { require.NoError(t, tp.Shutdown(context.Background())) }()
+	tracer := tp.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "ok")
+	span.SetStatus(codes.Ok, "")
+	span.End()
+	_, span = tracer.Start(context.Background(), "unset")
+	span.End()
+	_, span = tracer.Start(context.Background(), "error")
+	span.SetStatus(codes.Error, "failed")
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "error", spans[0].Name)
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+	require.True(t, spans[0].SpanContext.IsSampled())
+}
+
+func TestErrorSamplerKeepsSampledSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewErrorSampler(sdktrace.AlwaysSample())),
+		sdktrace.WithSpanProcessor(NewErrorSpanProcessor(sdktrace.NewSimpleSpanProcessor(exporter))),
+	)
+	defer func() { require.NoError(t, tp.Shutdown(context.Background())) }()
+
+	_, span := tp.Tracer("test").Start(context.Background(), "ok")
+	span.End()
+
+	require.Len(t, exporter.GetSpans(), 1)
+	require.Equal(t, "ErrorSampler{AlwaysOnSampler}", NewErrorSampler(sdktrace.AlwaysSample()).Description())
+}
@@ -0,0 +1,27 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsetup
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLogger(t *testing.T) {
+	defer SetLogger(nil)
+	require.Same(t, slog.Default(), Logger())
+
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+	SetLogger(l)
+	require.Same(t, l, Logger())
+	Logger().Info("injected")
+	require.Contains(t, buf.String(), "msg=injected")
+
+	SetLogger(nil)
+	require.Same(t, slog.Default(), Logger(), "a nil logger should restore the default one")
+}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsetup
+
+import (
+	"context"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// RetryConfig configures how failed exports are retried. Retries stop once
+// the export times out.
+type RetryConfig struct {
+	// MaxAttempts is the number of attempts of an export, failed exports are
+	// not retried when it is at most 1
+	MaxAttempts int
+	// Backoff is the wait before the first retry, it doubles before each of
+	// the following ones
+	Backoff time.Duration
+}
+
+func (r RetryConfig) enabled() bool {
+	return r.MaxAttempts > 1
+}
+
+// do calls export until it succeeds, MaxAttempts is reached or ctx is done
+func (r RetryConfig) do(ctx context.Context, export func(context.Context) error) error {
+	backoff := r.Backoff
+	for attempt := 1; ; attempt++ {
+		err := export(ctx)
+		if err == nil || attempt >= r.MaxAttempts {
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func (r RetryConfig) spanExporter(exporter sdktrace.SpanExporter) sdktrace.SpanExporter {
+	if !r.enabled() {
+		return exporter
+	}
+	return retryingSpanExporter{SpanExporter: exporter, retry: r}
+}
+
+func (r RetryConfig) metricExporter(exporter sdkmetric.Exporter) sdkmetric.Exporter {
+	if !r.enabled() {
+		return exporter
+	}
+	return retryingMetricExporter{Exporter: exporter, retry: r}
+}
+
+type retryingSpanExporter struct {
+	sdktrace.SpanExporter
+	retry RetryConfig
+}
+
+func (e retryingSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	return e.retry.do(ctx, func(ctx context.Context) error {
+		return e.SpanExporter.ExportSpans(ctx, spans)
+	})
+}
+
+type retryingMetricExporter struct {
+	sdkmetric.Exporter
+	retry RetryConfig
+}
+
+func (e retryingMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	return e.retry.do(ctx, func(ctx context.Context) error {
+		return e.Exporter.Export(ctx, rm)
+	})
+}
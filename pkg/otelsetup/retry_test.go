@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsetup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// failingExporter fails the first failures exports
+type failingExporter struct {
+	failures int32
+	exports  atomic.Int32
+}
+
+func (e *failingExporter) ExportSpans(context.Context, []sdktrace.ReadOnlySpan) error {
+	if e.exports.Add(1) <= e.failures {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func (*failingExporter) Shutdown(context.Context) error { return nil }
+
+func TestRetryConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		retry    RetryConfig
+		failures int32
+		exports  int32
+		fails    bool
+	}{
+		{name: "disabled", failures: 1, exports: 1, fails: true},
+		{name: "succeeds", retry: RetryConfig{MaxAttempts: 3, Backoff: time.Millisecond}, failures: 2, exports: 3},
+		{name: "exhausted", retry: RetryConfig{MaxAttempts: 2, Backoff: time.Millisecond}, failures: 2, exports: 2, fails: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := &failingExporter{failures: tt.failures}
+			err := tt.retry.spanExporter(exporter).ExportSpans(context.Background(), nil)
+			require.Equal(t, tt.fails, err != nil)
+			require.Equal(t, tt.exports, exporter.exports.Load())
+		})
+	}
+}
+
+func TestRetryConfigStopsAtTimeout(t *testing.T) {
+	exporter := &failingExporter{failures: 100}
+	retry := RetryConfig{MaxAttempts: 100, Backoff: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	require.Error(t, retry.spanExporter(exporter).ExportSpans(ctx, nil))
+	require.Less(t, time.Since(start), time.Second, "the backoff should end with the export")
+	require.Equal(t, int32(1), exporter.exports.Load())
+}
@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsetup
+
+import (
+	"context"
+	"errors"
+)
+
+// Shutdowner is implemented by the providers of the SDK, e.g. the
+// TracerProvider and the MeterProvider.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown shuts the providers down concurrently. It returns once they are,
+// or when ctx is done, so that a stalled collector cannot block the exit of
+// the application past the deadline of ctx.
+func Shutdown(ctx context.Context, providers ...Shutdowner) error {
+	errs := make(chan error, len(providers))
+	for _, p := range providers {
+		go func() {
+			errs <- p.Shutdown(ctx)
+		}()
+	}
+	var err error
+	for range providers {
+		select {
+		case e := <-errs:
+			err = errors.Join(err, e)
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		}
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsetup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// stalledExporter never completes an export, it only gives up when its
+// context is done, or never with ignoreContext
+type stalledExporter struct {
+	ignoreContext bool
+	release       chan struct{}
+}
+
+func (e *stalledExporter) ExportSpans(ctx context.Context, _ []sdktrace.ReadOnlySpan) error {
+	if e.ignoreContext {
+		<-e.release
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-e.release:
+		return nil
+	}
+}
+
+func (*stalledExporter) Shutdown(context.Context) error { return nil }
+
+func TestShutdownWithStalledExporter(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		config   Config
+		exporter *stalledExporter
+		timeout  time.Duration
+	}{
+		{
+			name:     "export timeout",
+			config:   Config{ExportTimeout: 50 * time.Millisecond},
+			exporter: &stalledExporter{release: make(chan struct{})},
+			timeout:  time.Minute,
+		},
+		{
+			name:     "shutdown deadline",
+			exporter: &stalledExporter{ignoreContext: true, release: make(chan struct{})},
+			timeout:  50 * time.Millisecond,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer close(tt.exporter.release)
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewSpanProcessor(tt.config, tt.exporter)))
+			_, span := tp.Tracer("test").Start(context.Background(), "span")
+			span.End()
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			start := time.Now()
+			_ = Shutdown(ctx, tp)
+			require.Less(t, time.Since(start), 5*time.Second, "shutdown should not wait for the stalled exporter")
+		})
+	}
+}
+
+type failingShutdowner struct{ err error }
+
+func (s failingShutdowner) Shutdown(context.Context) error { return s.err }
+
+func TestShutdownJoinsErrors(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	require.NoError(t, Shutdown(context.Background(), tp))
+
+	errA, errB := errors.New("a"), errors.New("b")
+	err := Shutdown(context.Background(), failingShutdowner{errA}, sdktrace.NewTracerProvider(), failingShutdowner{errB})
+	require.ErrorIs(t, err, errA)
+	require.ErrorIs(t, err, errB)
+}
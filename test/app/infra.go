@@ -5,10 +5,13 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -29,7 +32,9 @@ func newCmd(ctx context.Context, dir string, args ...string) *exec.Cmd {
 	return cmd
 }
 
-// Build builds the application with the instrumentation tool.
+// Build builds the application with the instrumentation tool. The binary is
+// reused when the application was already built with the same arguments
+// during the test run, e.g. by another subtest.
 func Build(t *testing.T, appDir string, args ...string) {
 	binName := "otel"
 	if util.IsWindows() {
@@ -38,11 +43,63 @@ func Build(t *testing.T, appDir string, args ...string) {
 	pwd, err := os.Getwd()
 	require.NoError(t, err)
 	otelPath := filepath.Join(pwd, "..", "..", binName)
-	args = append([]string{otelPath}, args...)
+	dir, err := filepath.Abs(appDir)
+	require.NoError(t, err)
 
-	cmd := newCmd(t.Context(), appDir, args...)
-	out, err := cmd.CombinedOutput()
-	require.NoError(t, err, string(out))
+	err = builds.build(dir, args, func() error {
+		cmd := newCmd(t.Context(), appDir, append([]string{otelPath}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// builds are the applications built during the test run
+var builds = &buildCache{apps: make(map[string]*builtApp)}
+
+// buildCache tracks the arguments of the binary of each application directory.
+// Builds of different applications run concurrently, those of the same
+// application are serialized as they write the same binary.
+type buildCache struct {
+	mu   sync.Mutex
+	apps map[string]*builtApp
+}
+
+type builtApp struct {
+	mu sync.Mutex
+	// args are the arguments of the binary in the directory, empty when it is
+	// not known to be up to date
+	args string
+}
+
+// build runs build unless the binary of dir is already built with args
+func (c *buildCache) build(dir string, args []string, build func() error) error {
+	c.mu.Lock()
+	app, ok := c.apps[dir]
+	if !ok {
+		app = &builtApp{}
+		c.apps[dir] = app
+	}
+	c.mu.Unlock()
+
+	// The prefix tells a build without arguments apart from an unknown binary
+	key := strings.Join(append([]string{"build"}, args...), "\x00")
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.args == key {
+		if _, err := os.Stat(filepath.Join(dir, filepath.Base(dir))); err == nil {
+			return nil
+		}
+	}
+	app.args = ""
+	if err := build(); err != nil {
+		return err
+	}
+	app.args = key
+	return nil
 }
 
 // Run runs the application and returns the output.
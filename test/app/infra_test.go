@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "demo")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	binary := filepath.Join(dir, "demo")
+	var count atomic.Int32
+	build := func() error {
+		count.Add(1)
+		return os.WriteFile(binary, nil, 0o755)
+	}
+	cache := &buildCache{apps: make(map[string]*builtApp)}
+
+	// Concurrent builds of the same application run once
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, cache.build(dir, []string{"go", "build"}, build))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), count.Load(), "the second build should be a cache hit")
+
+	// Other arguments overwrite the binary, building it again
+	require.NoError(t, cache.build(dir, []string{"go", "build", "-a"}, build))
+	require.NoError(t, cache.build(dir, []string{"go", "build"}, build))
+	require.Equal(t, int32(3), count.Load())
+
+	// A removed binary is built again
+	require.NoError(t, os.Remove(binary))
+	require.NoError(t, cache.build(dir, []string{"go", "build"}, build))
+	require.Equal(t, int32(4), count.Load())
+
+	// Failed builds are not cached
+	failed := errors.New("build failed")
+	require.ErrorIs(t, cache.build(dir, nil, func() error { count.Add(1); return failed }), failed)
+	require.NoError(t, cache.build(dir, nil, build))
+	require.Equal(t, int32(6), count.Load())
+}
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// metricsPollInterval is how often WaitForMetrics collects from the reader.
+const metricsPollInterval = 50 * time.Millisecond
+
+// WaitForMetrics collects from the reader until a metric with the given name
+// is present, failing the test if it does not show up within the timeout.
+// It returns the first matching metric.
+func WaitForMetrics(t *testing.T, reader sdkmetric.Reader, name string, timeout time.Duration) metricdata.Metrics {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		rm := metricdata.ResourceMetrics{}
+		require.NoError(t, reader.Collect(t.Context(), &rm))
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name == name {
+					return m
+				}
+			}
+		}
+		if time.Now().After(deadline) {
+			require.Failf(t, "metric not found", "metric %q was not recorded within %s", name, timeout)
+		}
+		time.Sleep(metricsPollInterval)
+	}
+}
+
+// RequireSumTemporality verifies that the metric is a sum with the expected
+// temporality.
+func RequireSumTemporality(t *testing.T, m metricdata.Metrics, temporality metricdata.Temporality) {
+	t.Helper()
+	var actual metricdata.Temporality
+	switch data := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		actual = data.Temporality
+	case metricdata.Sum[float64]:
+		actual = data.Temporality
+	default:
+		require.Failf(t, "not a sum", "metric %q is a %T", m.Name, m.Data)
+	}
+	require.Equal(t, temporality, actual, "temporality of metric %q", m.Name)
+}
+
+// RequireSumMonotonic verifies that the metric is a sum which is monotonic,
+// i.e. a counter, or not, i.e. an up-down counter.
+func RequireSumMonotonic(t *testing.T, m metricdata.Metrics, monotonic bool) {
+	t.Helper()
+	var actual bool
+	switch data := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		actual = data.IsMonotonic
+	case metricdata.Sum[float64]:
+		actual = data.IsMonotonic
+	default:
+		require.Failf(t, "not a sum", "metric %q is a %T", m.Name, m.Data)
+	}
+	require.Equal(t, monotonic, actual, "monotonicity of metric %q", m.Name)
+}
+
+// RequireHistogramTemporality verifies that the metric is a histogram with
+// the expected temporality.
+func RequireHistogramTemporality(t *testing.T, m metricdata.Metrics, temporality metricdata.Temporality) {
+	t.Helper()
+	var actual metricdata.Temporality
+	switch data := m.Data.(type) {
+	case metricdata.Histogram[int64]:
+		actual = data.Temporality
+	case metricdata.Histogram[float64]:
+		actual = data.Temporality
+	default:
+		require.Failf(t, "not a histogram", "metric %q is a %T", m.Name, m.Data)
+	}
+	require.Equal(t, temporality, actual, "temporality of metric %q", m.Name)
+}
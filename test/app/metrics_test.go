@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRequireTemporality(t *testing.T) {
+	reader := sdkmetric.NewManualReader(sdkmetric.WithTemporalitySelector(
+		func(sdkmetric.InstrumentKind) metricdata.Temporality { return metricdata.DeltaTemporality },
+	))
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(t.Context())
+	meter := provider.Meter("test")
+
+	counter, err := meter.Int64Counter("requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.Add(t.Context(), 1)
+	upDownCounter, err := meter.Float64UpDownCounter("active")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upDownCounter.Add(t.Context(), 1)
+	histogram, err := meter.Float64Histogram("duration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	histogram.Record(t.Context(), 0.5)
+
+	// Delta readers only report the measurements since the last collection,
+	// so collect once and look the metrics up
+	rm := metricdata.ResourceMetrics{}
+	if err = reader.Collect(t.Context(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	metrics := make(map[string]metricdata.Metrics)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+	RequireSumTemporality(t, metrics["requests"], metricdata.DeltaTemporality)
+	RequireSumMonotonic(t, metrics["requests"], true)
+	RequireSumTemporality(t, metrics["active"], metricdata.DeltaTemporality)
+	RequireSumMonotonic(t, metrics["active"], false)
+	RequireHistogramTemporality(t, metrics["duration"], metricdata.DeltaTemporality)
+}
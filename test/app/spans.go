@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ForceFlush synchronously exports the spans buffered by the processors of
+// provider, e.g. a batch span processor, so that they can be asserted without
+// polling the exporter. The test fails when flushing takes longer than timeout.
+func ForceFlush(t *testing.T, provider *sdktrace.TracerProvider, timeout time.Duration) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(t.Context(), timeout)
+	defer cancel()
+	require.NoError(t, provider.ForceFlush(ctx), "failed to flush spans")
+}
+
+// SpanNode describes an expected span and its children. Kind is compared only
+// when it is set, i.e. not trace.SpanKindUnspecified.
+type SpanNode struct {
+	Name     string
+	Kind     trace.SpanKind
+	Children []SpanNode
+}
+
+// RequireSpanTree verifies that the spans form exactly the expected tree. A
+// span is the child of another one when they share the trace ID and its
+// parent span ID is the span ID of the other one. Siblings are compared in
+// name order, so the order in which they ended does not matter. On mismatch,
+// the test fails with a diff of the actual and expected trees.
+func RequireSpanTree(t *testing.T, spans []sdktrace.ReadOnlySpan, expected SpanNode) {
+	t.Helper()
+	children := make(map[spanID][]sdktrace.ReadOnlySpan)
+	known := make(map[spanID]bool)
+	for _, span := range spans {
+		known[spanKey(span.SpanContext())] = true
+	}
+	var roots []sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		parent := spanKey(span.Parent())
+		if span.Parent().IsValid() && known[parent] {
+			children[parent] = append(children[parent], span)
+		} else {
+			roots = append(roots, span)
+		}
+	}
+
+	var want, got strings.Builder
+	renderExpected(&want, expected, 0)
+	sortSpans(roots)
+	for i, root := range roots {
+		// Only the first root is paired with the expected tree, any other
+		// root is unexpected and rendered with its kind
+		var exp *SpanNode
+		if i == 0 {
+			exp = &expected
+		}
+		renderActual(&got, root, children, exp, 0)
+	}
+	require.Equal(t, want.String(), got.String(), "span tree mismatch")
+}
+
+// spanID identifies a span regardless of its flags and trace state
+type spanID struct {
+	traceID trace.TraceID
+	spanID  trace.SpanID
+}
+
+func spanKey(sc trace.SpanContext) spanID {
+	return spanID{traceID: sc.TraceID(), spanID: sc.SpanID()}
+}
+
+func sortSpans(spans []sdktrace.ReadOnlySpan) {
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Name() != spans[j].Name() {
+			return spans[i].Name() < spans[j].Name()
+		}
+		return spans[i].StartTime().Before(spans[j].StartTime())
+	})
+}
+
+func renderExpected(b *strings.Builder, node SpanNode, depth int) {
+	writeNode(b, node.Name, node.Kind, depth)
+	nodes := append([]SpanNode(nil), node.Children...)
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	for _, child := range nodes {
+		renderExpected(b, child, depth+1)
+	}
+}
+
+func renderActual(b *strings.Builder, span sdktrace.ReadOnlySpan,
+	children map[spanID][]sdktrace.ReadOnlySpan, expected *SpanNode, depth int,
+) {
+	kind := span.SpanKind()
+	var expectedChildren []SpanNode
+	if expected != nil {
+		if expected.Kind == trace.SpanKindUnspecified {
+			kind = trace.SpanKindUnspecified
+		}
+		expectedChildren = append(expectedChildren, expected.Children...)
+		sort.SliceStable(expectedChildren, func(i, j int) bool {
+			return expectedChildren[i].Name < expectedChildren[j].Name
+		})
+	}
+	writeNode(b, span.Name(), kind, depth)
+	spans := children[spanKey(span.SpanContext())]
+	sortSpans(spans)
+	for i, child := range spans {
+		var exp *SpanNode
+		if i < len(expectedChildren) {
+			exp = &expectedChildren[i]
+		}
+		renderActual(b, child, children, exp, depth+1)
+	}
+}
+
+func writeNode(b *strings.Builder, name string, kind trace.SpanKind, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(name)
+	if kind != trace.SpanKindUnspecified {
+		fmt.Fprintf(b, " (%s)", kind)
+	}
+	b.WriteString("\n")
+}
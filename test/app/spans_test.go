@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestForceFlush(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	// The batch would not be exported on its own during the test
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter,
+		sdktrace.WithBatchTimeout(time.Hour),
+		sdktrace.WithMaxExportBatchSize(100),
+	))
+	defer provider.Shutdown(t.Context())
+	tracer := provider.Tracer("test")
+
+	ctx, parent := tracer.Start(t.Context(), "parent", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "child", trace.WithSpanKind(trace.SpanKindClient))
+	child.End()
+	parent.End()
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Fatalf("expected the spans to be buffered, got %d exported", len(spans))
+	}
+
+	ForceFlush(t, provider, time.Second)
+	RequireSpanTree(t, exporter.GetSpans().Snapshots(), SpanNode{
+		Name: "parent",
+		Kind: trace.SpanKindServer,
+		Children: []SpanNode{
+			{Name: "child", Kind: trace.SpanKindClient},
+		},
+	})
+}
@@ -7,10 +7,14 @@ package test
 
 import (
 	"bufio"
+	"encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -56,6 +60,35 @@ func waitUntilReady(t *testing.T, serverApp *exec.Cmd, outputPipe io.ReadCloser)
 	}
 }
 
+// loggedSpan is a span logged by the demo applications run with -log-spans
+type loggedSpan struct {
+	Name         string `json:"span_name"`
+	Kind         string `json:"span_kind"`
+	TraceID      string `json:"trace_id"`
+	SpanID       string `json:"span_id"`
+	ParentSpanID string `json:"parent_span_id"`
+}
+
+// parseSpans returns the spans of the given kind logged in output
+func parseSpans(t *testing.T, output, kind string) []loggedSpan {
+	t.Helper()
+
+	var spans []loggedSpan
+	for _, line := range strings.Split(output, "\n") {
+		var entry struct {
+			Msg string `json:"msg"`
+			loggedSpan
+		}
+		if json.Unmarshal([]byte(line), &entry) != nil || entry.Msg != "span ended" {
+			continue
+		}
+		if entry.Kind == kind {
+			spans = append(spans, entry.loggedSpan)
+		}
+	}
+	return spans
+}
+
 func TestHttp(t *testing.T) {
 	serverDir := filepath.Join("..", "..", "demo", "http", "server")
 	clientDir := filepath.Join("..", "..", "demo", "http", "client")
@@ -65,15 +98,57 @@ func TestHttp(t *testing.T) {
 	app.Build(t, clientDir, "go", "build", "-a")
 
 	// Start the server and wait for it to be ready.
-	serverApp, outputPipe := app.Start(t, serverDir)
+	serverApp, outputPipe := app.Start(t, serverDir, "-no-faults", "-no-latency", "-log-spans")
 	waitUntilDone := waitUntilReady(t, serverApp, outputPipe)
 
+	// Send a request, the server span ends before the response is flushed.
+	clientOutput := app.Run(t, clientDir, "-log-spans")
+
 	// Run the client, it will send a shutdown request to the server.
 	app.Run(t, clientDir, "-shutdown")
 
 	// Wait for the server to exit and return the output.
-	output := waitUntilDone()
+	serverOutput := waitUntilDone()
+
+	clientSpans := parseSpans(t, clientOutput, "client")
+	require.Len(t, clientSpans, 1, clientOutput)
+	serverSpans := parseSpans(t, serverOutput, "server")
+	require.Len(t, serverSpans, 1, serverOutput)
+
+	// The trace context crossed the process boundary
+	client, server := clientSpans[0], serverSpans[0]
+	require.Equal(t, client.TraceID, server.TraceID, "the client and the server should share the trace")
+	require.Equal(t, client.SpanID, server.ParentSpanID, "the server span should be a child of the client span")
+}
+
+func TestHttpPostBody(t *testing.T) {
+	clientDir := filepath.Join("..", "..", "demo", "http", "client")
+	app.Build(t, clientDir, "go", "build", "-a")
+
+	// The mock server echoes the body it received
+	var mu sync.Mutex
+	var received [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, body)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	output := app.Run(t, clientDir, "-addr", server.URL, "-method", "POST", "-name", "body-check", "-count-spans")
+	// The round trip went through the instrumented transport
+	require.Contains(t, output, `"client_spans":1`, output)
+	require.Contains(t, output, "request successful")
 
-	// Verify that the server hook was called.
-	require.Contains(t, output, "BeforeServeHTTP")
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	require.Equal(t, `{"name":"body-check"}`, string(received[0]), "the body should reach the server untouched")
 }
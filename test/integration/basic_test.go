@@ -32,6 +32,7 @@ func TestBasic(t *testing.T) {
 		"paramCount:1",
 		"returnValCount:0",
 		"isSkipCall:false",
+		"variadic:items [1 2 3]",
 	}
 	for _, e := range expect {
 		require.Contains(t, output, e)
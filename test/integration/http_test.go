@@ -0,0 +1,39 @@
+//go:build integration
+
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"bufio"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/app"
+)
+
+func TestHTTPClientCustomTransport(t *testing.T) {
+	serverDir := filepath.Join("..", "..", "demo", "http", "server")
+	clientDir := filepath.Join("..", "..", "demo", "http", "client")
+
+	app.Build(t, serverDir, "go", "build", "-a")
+	app.Build(t, clientDir, "go", "build", "-a")
+	_, output := app.Start(t, serverDir, "-port", "18080", "-no-faults", "-no-latency")
+	scanner := bufio.NewScanner(output)
+	started := false
+	for !started && scanner.Scan() {
+		started = strings.Contains(scanner.Text(), "server started")
+	}
+	require.True(t, started, "the server should start")
+
+	clientOutput := app.Run(t, clientDir, "-addr", "http://localhost:18080", "-count", "2",
+		"-custom-transport", "-count-spans")
+	// The custom transport wraps http.Transport, whose round trips are
+	// instrumented once per request
+	require.Contains(t, clientOutput, `"successful":2`)
+	require.Contains(t, clientOutput, `"client_spans":2`, clientOutput)
+}
@@ -0,0 +1,44 @@
+//go:build integration
+
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/app"
+)
+
+// requireInOrder checks that the expected lines appear in output in order
+func requireInOrder(t *testing.T, output string, expect ...string) {
+	rest := output
+	for _, e := range expect {
+		index := strings.Index(rest, e)
+		require.NotEqual(t, -1, index, "%q not found in order in output:\n%s", e, output)
+		rest = rest[index+len(e):]
+	}
+}
+
+func TestMainPackage(t *testing.T) {
+	appDir := filepath.Join("..", "..", "demo", "basic")
+
+	app.Build(t, appDir, "go", "build", "-a")
+	output := app.Run(t, appDir)
+	// Both hooks fire around the function and the method declared in main
+	requireInOrder(t, output,
+		"[MyHook] start to instrument hello world!",
+		"[MyHook] hello world is instrumented!",
+		"[MyHook] after hook executed!",
+	)
+	requireInOrder(t, output,
+		"Before MyStruct.Example()",
+		"MyStruct.Example",
+		"After MyStruct.Example()",
+	)
+}
@@ -0,0 +1,31 @@
+//go:build integration
+
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/test/app"
+)
+
+func TestNoopBuildTag(t *testing.T) {
+	appDir := filepath.Join("..", "..", "demo", "http", "server")
+
+	// Rebuild everything, the build cache may hold the standard library as
+	// compiled by the instrumented builds of the other tests
+	app.Build(t, appDir, "go", "build", "-a", "-tags", "otelnoop")
+	cmd := exec.Command("go", "tool", "nm", filepath.Base(appDir))
+	cmd.Dir = appDir
+	symbols, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(symbols))
+	// Neither the hooks nor the OpenTelemetry SDK are linked
+	require.NotContains(t, string(symbols), "opentelemetry-go-compile-instrumentation/pkg")
+	require.NotContains(t, string(symbols), "go.opentelemetry.io/otel")
+}
@@ -33,6 +33,21 @@ func findFuncDecls(root *dst.File, lambda func(*dst.FuncDecl) bool) []*dst.FuncD
 	return found
 }
 
+// CountStmts counts the statements of node, including the nested ones. Blocks
+// are not statements of their own, only their content is counted.
+func CountStmts(node dst.Node) int {
+	count := 0
+	dst.Inspect(node, func(n dst.Node) bool {
+		switch n.(type) {
+		case *dst.BlockStmt, *dst.EmptyStmt:
+		case dst.Stmt:
+			count++
+		}
+		return true
+	})
+	return count
+}
+
 func FindFuncDeclWithoutRecv(root *dst.File, funcName string) *dst.FuncDecl {
 	decls := findFuncDecls(root, func(funcDecl *dst.FuncDecl) bool {
 		return funcDecl.Name.Name == funcName && !HasReceiver(funcDecl)
@@ -28,6 +28,15 @@ func listRuleFiles(path string) ([]string, error) {
 	return util.ListFiles(p)
 }
 
+// fileRuleOutput is the file of the working directory the file introduced by
+// rule is written to
+func (ip *InstrumentPhase) fileRuleOutput(rule *rule.InstFileRule) string {
+	base := filepath.Base(rule.File)
+	ext := filepath.Ext(base)
+	newName := strings.TrimSuffix(base, ext)
+	return filepath.Join(ip.workDir, fmt.Sprintf("otel.%s.go", newName))
+}
+
 // applyFileRule introduces the new file to the target package at compile time.
 func (ip *InstrumentPhase) applyFileRule(rule *rule.InstFileRule, pkgName string) error {
 	// List all files in the rule module path
@@ -54,10 +63,7 @@ func (ip *InstrumentPhase) applyFileRule(rule *rule.InstFileRule, pkgName string
 	root.Name.Name = pkgName
 
 	// Write back the modified AST to a new file in the working directory
-	base := filepath.Base(rule.File)
-	ext := filepath.Ext(base)
-	newName := strings.TrimSuffix(base, ext)
-	newFile := filepath.Join(ip.workDir, fmt.Sprintf("otel.%s.go", newName))
+	newFile := ip.fileRuleOutput(rule)
 	err = ast.WriteFile(newFile, root)
 	if err != nil {
 		return err
@@ -22,13 +22,33 @@ const (
 	otelGlobalsFile = "otel.globals.go"
 )
 
+// makeName names the trampoline functions after the configured prefix, the
+// target function and the rule, e.g. OtelBeforeTrampoline_Foo1234
 func makeName(r *rule.InstFuncRule, funcDecl *dst.FuncDecl, isBefore bool) string {
-	prefix := trampolineAfterName
+	name := trampolineAfterSuffix
 	if isBefore {
-		prefix = trampolineBeforeName
+		name = trampolineBeforeSuffix
 	}
-	return fmt.Sprintf("%s_%s%s",
-		prefix, funcDecl.Name.Name, util.CRC32(r.String()))
+	return fmt.Sprintf("%s%s_%s%s",
+		util.GetTrampolinePrefix(), name, funcDecl.Name.Name, util.CRC32(r.String()))
+}
+
+// isInstrumented reports whether the function already calls the trampolines of
+// the rule. Trampoline names are derived from the rule, so the function can
+// still be instrumented by other rules.
+func isInstrumented(r *rule.InstFuncRule, funcDecl *dst.FuncDecl) bool {
+	if funcDecl.Body == nil {
+		return false
+	}
+	before, after := makeName(r, funcDecl, true), makeName(r, funcDecl, false)
+	found := false
+	dst.Inspect(funcDecl.Body, func(node dst.Node) bool {
+		if ident, ok := node.(*dst.Ident); ok && (ident.Name == before || ident.Name == after) {
+			found = true
+		}
+		return !found
+	})
+	return found
 }
 
 func findJumpPoint(jumpIf *dst.IfStmt) *dst.BlockStmt {
@@ -48,44 +68,60 @@ func findJumpPoint(jumpIf *dst.IfStmt) *dst.BlockStmt {
 	return nil
 }
 
+// collectReturnValues names the unnamed and blank return values of funcDecl,
+// e.g. _unnamedRetVal0, so that they can be referenced, and returns the names
+// of all its return values.
 func collectReturnValues(funcDecl *dst.FuncDecl) []string {
-	// Add explicit names for return values, they can be further referenced if
-	// we're willing
-	var retVals []string // nil by default
-	if retList := funcDecl.Type.Results; retList != nil {
-		idx := 0
-		for _, field := range retList.List {
-			if field.Names == nil {
-				// Rename (for referenceability)
-				name := fmt.Sprintf("%s%d", unnamedRetValName, idx)
-				field.Names = []*dst.Ident{ast.Ident(name)}
-				idx++
-				// Collect (for further use)
-				retVals = append(retVals, name)
-			} else {
-				// Collect only (for further use)
-				for _, name := range field.Names {
-					retVals = append(retVals, name.Name)
-				}
-			}
-		}
-	}
-
-	return retVals
+	return nameFields(funcDecl, unnamedRetValName, funcDecl.Type.Results)
 }
 
+// collectArguments names the unnamed and blank receiver and parameters of
+// funcDecl, e.g. _unnamedParam0, so that they can be passed to the hooks, and
+// returns the names of all of them, the receiver first.
 func collectArguments(funcDecl *dst.FuncDecl) []string {
-	args := make([]string, 0)
-	if ast.HasReceiver(funcDecl) {
-		receiver := funcDecl.Recv.List[0].Names[0].Name
-		args = append(args, receiver)
+	return nameFields(funcDecl, unnamedParamName, funcDecl.Recv, funcDecl.Type.Params)
+}
+
+// nameFields names the unnamed and blank fields of lists after prefix and
+// returns the names of all the fields. The suffix of a name already declared
+// by the receiver, the parameters or the return values of funcDecl is skipped,
+// so that the names are unique within the function.
+func nameFields(funcDecl *dst.FuncDecl, prefix string, lists ...*dst.FieldList) []string {
+	declared := make(map[string]bool)
+	for _, list := range []*dst.FieldList{funcDecl.Recv, funcDecl.Type.Params, funcDecl.Type.Results} {
+		for _, name := range getNames(list) {
+			declared[name] = true
+		}
 	}
-	for _, field := range funcDecl.Type.Params.List {
-		for _, name := range field.Names {
-			args = append(args, name.Name)
+	idx := 0
+	nextName := func() string {
+		for {
+			name := fmt.Sprintf("%s%d", prefix, idx)
+			idx++
+			if !declared[name] {
+				declared[name] = true
+				return name
+			}
 		}
 	}
-	return args
+	var names []string
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			if field.Names == nil {
+				field.Names = []*dst.Ident{ast.Ident(nextName())}
+			}
+			for _, name := range field.Names {
+				if name.Name == ast.IdentIgnore {
+					name.Name = nextName()
+				}
+				names = append(names, name.Name)
+			}
+		}
+	}
+	return names
 }
 
 func createHookArgs(names []string) []dst.Expr {
@@ -247,9 +283,10 @@ func (ip *InstrumentPhase) addCompileArg(newArg string) {
 var templateAPI string
 
 func (ip *InstrumentPhase) writeGlobals(pkgName string) error {
-	// Prepare trampoline code header
+	// Prepare trampoline code header, stamped with the inputs it is generated
+	// from so that later runs can reuse it
 	p := ast.NewAstParser()
-	trampoline, err := p.ParseSource("package " + pkgName)
+	trampoline, err := p.ParseSource(stampPrefix + ip.stamp + "\n\npackage " + pkgName)
 	if err != nil {
 		return err
 	}
@@ -283,7 +320,17 @@ func (ip *InstrumentPhase) writeInstrumented(root *dst.File, oldFile string) err
 	}
 	ip.keepForDebug(newFile)
 
-	// Replace the original file with the new file in the compile command
+	err = ip.replaceCompileArg(oldFile, newFile)
+	if err != nil {
+		return err
+	}
+	ip.Info("Write instrumented AST", "old", oldFile, "new", newFile)
+	return nil
+}
+
+// replaceCompileArg replaces the original file with the new file in the
+// compile command
+func (ip *InstrumentPhase) replaceCompileArg(oldFile, newFile string) error {
 	replace := false
 	for i, arg := range ip.compileArgs {
 		// Files in the compile command maybe relative or absolute, we need to
@@ -302,7 +349,6 @@ func (ip *InstrumentPhase) writeInstrumented(root *dst.File, oldFile string) err
 		return ex.Newf("cannot replace %s with %s during %v",
 			oldFile, newFile, ip.compileArgs)
 	}
-	ip.Info("Write instrumented AST", "old", oldFile, "new", newFile)
 	return nil
 }
 
@@ -325,6 +371,13 @@ func (ip *InstrumentPhase) applyFuncRule(rule *rule.InstFuncRule, root *dst.File
 	if funcDecl == nil {
 		return ex.Newf("can not find function %s", rule.Func)
 	}
+	// The function may have been instrumented already, e.g. when the tool runs
+	// twice over the same source, do not wrap it again
+	if isInstrumented(rule, funcDecl) {
+		ip.Warn("Skip already instrumented function", "rule", rule, "func", rule.Func)
+		// Its trampolines still refer to the global variables
+		return ip.materializeVarDecls()
+	}
 
 	err := ip.insertTJump(rule, funcDecl)
 	if err != nil {
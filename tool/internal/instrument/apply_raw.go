@@ -4,8 +4,6 @@
 package instrument
 
 import (
-	"fmt"
-
 	"github.com/dave/dst"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
@@ -14,26 +12,16 @@ import (
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
 )
 
-const unnamedRetValName = "_unnamedRetVal"
-
-func renameReturnValues(funcDecl *dst.FuncDecl) {
-	if retList := funcDecl.Type.Results; retList != nil {
-		idx := 0
-		for _, field := range retList.List {
-			if field.Names == nil {
-				name := fmt.Sprintf("%s%d", unnamedRetValName, idx)
-				field.Names = []*dst.Ident{ast.Ident(name)}
-				idx++
-			}
-		}
-	}
-}
+const (
+	unnamedRetValName = "_unnamedRetVal"
+	unnamedParamName  = "_unnamedParam"
+)
 
 func insertRaw(r *rule.InstRawRule, decl *dst.FuncDecl) error {
 	util.Assert(decl.Name.Name == r.Func, "sanity check")
 
-	// Rename the unnamed return values so that the raw code can reference them
-	renameReturnValues(decl)
+	// Name the unnamed return values so that the raw code can reference them
+	collectReturnValues(decl)
 	// Parse the raw code into AST statements
 	p := ast.NewAstParser()
 	stmts, err := p.ParseSnippet(r.Raw)
@@ -16,10 +16,13 @@ package instrument
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"testing"
@@ -85,6 +88,118 @@ func runTest(t *testing.T, testName string) {
 	verifyGoldenFiles(t, tempDir, testName)
 }
 
+func TestInstrumentation_Twice(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv(util.EnvOtelWorkDir, tempDir)
+	ctx := util.ContextWithLogger(t.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	sourceFile := filepath.Join(tempDir, mainGoFileName)
+	util.CopyFile(filepath.Join(testdataDir, sourceFileName), sourceFile)
+	writeMatchedJSON(loadRulesYAML(t, "multiple-func-rules", sourceFile))
+	args := compileArgs(tempDir, sourceFile)
+
+	// The instrumented file replaces the source, so the second run sees the
+	// trampoline calls of the first one
+	require.NoError(t, Toolexec(ctx, args))
+	first, err := os.ReadFile(sourceFile)
+	require.NoError(t, err)
+	require.NoError(t, Toolexec(ctx, args))
+	second, err := os.ReadFile(sourceFile)
+	require.NoError(t, err)
+	require.Equal(t, string(first), string(second), "functions should not be wrapped twice")
+}
+
+func TestInstrumentation_TrampolinePrefix(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv(util.EnvOtelWorkDir, tempDir)
+	t.Setenv(util.EnvOtelTrampolinePrefix, "Acme")
+	ctx := util.ContextWithLogger(t.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	sourceFile := filepath.Join(tempDir, mainGoFileName)
+	util.CopyFile(filepath.Join(testdataDir, sourceFileName), sourceFile)
+	writeMatchedJSON(loadRulesYAML(t, "func-rule-only", sourceFile))
+	args := compileArgs(tempDir, sourceFile)
+
+	require.NoError(t, Toolexec(ctx, args))
+	first, err := os.ReadFile(sourceFile)
+	require.NoError(t, err)
+	require.Contains(t, string(first), "func AcmeBeforeTrampoline_Func1")
+	require.Contains(t, string(first), "func AcmeAfterTrampoline_Func1")
+	require.NotContains(t, string(first), trampolineBeforeName+"_", "all trampolines should be prefixed")
+
+	// Already instrumented functions are detected with the custom prefix
+	require.NoError(t, Toolexec(ctx, args))
+	second, err := os.ReadFile(sourceFile)
+	require.NoError(t, err)
+	require.Equal(t, string(first), string(second))
+
+	t.Setenv(util.EnvOtelTrampolinePrefix, "not-an-identifier")
+	require.ErrorContains(t, Toolexec(ctx, args), "invalid trampoline prefix")
+}
+
+func TestInstrumentation_Stamp(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv(util.EnvOtelWorkDir, tempDir)
+	ctx := util.ContextWithLogger(t.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// The source and the hooks live apart from the working directory, as in
+	// a build
+	sourceFile := filepath.Join(tempDir, "src", mainGoFileName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(sourceFile), 0o755))
+	util.CopyFile(filepath.Join(testdataDir, sourceFileName), sourceFile)
+	hookFile := filepath.Join(tempDir, "hooks", "hook.go")
+	util.CopyFile(filepath.Join(testdataDir, "hook.go"), hookFile)
+	writeRules := func(testName string) {
+		ruleSet := loadRulesYAML(t, testName, sourceFile)
+		for _, r := range ruleSet.FuncRules[sourceFile] {
+			r.Path = filepath.Dir(hookFile)
+		}
+		writeMatchedJSON(ruleSet)
+	}
+	// Every build compiles in a new working directory, as $WORK of go build
+	builds := 0
+	build := func() (workDir string) {
+		t.Helper()
+		builds++
+		workDir = filepath.Join(tempDir, fmt.Sprintf("work%d", builds))
+		require.NoError(t, os.MkdirAll(workDir, 0o755))
+		require.NoError(t, Toolexec(ctx, compileArgs(workDir, sourceFile)))
+		return workDir
+	}
+	generated := func(workDir string) (string, string) {
+		t.Helper()
+		instrumented, err := os.ReadFile(filepath.Join(workDir, mainGoFileName))
+		require.NoError(t, err)
+		return string(instrumented), readStamp(filepath.Join(workDir, otelGlobalsFile))
+	}
+
+	writeRules("func-rule-only")
+	instrumented, stamp := generated(build())
+	require.NotEmpty(t, stamp)
+
+	// Identical inputs reuse the files generated by the previous build
+	workDir := build()
+	require.NoFileExists(t, filepath.Join(workDir, mainGoFileName), "the files should not be generated again")
+	require.NoFileExists(t, filepath.Join(workDir, otelGlobalsFile))
+	reused, err := os.ReadFile(filepath.Join(util.GetBuildTemp(instrumentedCacheDir), stamp, mainGoFileName))
+	require.NoError(t, err)
+	require.Equal(t, instrumented, string(reused))
+
+	// Changed rules regenerate them
+	writeRules("multiple-func-rules")
+	regenerated, newStamp := generated(build())
+	require.NotEqual(t, stamp, newStamp)
+	require.NotEqual(t, instrumented, regenerated)
+
+	// So does a changed hook, the trampolines are generated from its
+	// signature
+	hook, err := os.ReadFile(hookFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(hookFile, append(hook, "\nfunc H1Helper() {}\n"...), 0o644))
+	_, hookStamp := generated(build())
+	require.NotEqual(t, newStamp, hookStamp)
+}
+
 func loadRulesYAML(t *testing.T, testName, sourceFile string) *rule.InstRuleSet {
 	data, err := os.ReadFile(filepath.Join(testdataDir, goldenDir, testName, rulesFileName))
 	require.NoError(t, err)
@@ -152,6 +267,8 @@ func compileArgs(tempDir, sourceFile string) []string {
 	}
 }
 
+var stampLine = regexp.MustCompile(`(?m)^` + stampPrefix + `\w+$`)
+
 func verifyGoldenFiles(t *testing.T, tempDir, testName string) {
 	entries, _ := os.ReadDir(filepath.Join(testdataDir, goldenDir, testName))
 	for _, entry := range entries {
@@ -160,6 +277,8 @@ func verifyGoldenFiles(t *testing.T, tempDir, testName string) {
 		}
 		actualFile := actualFileFromGolden(t, entry.Name())
 		actual, _ := os.ReadFile(filepath.Join(tempDir, actualFile))
+		// The stamp changes with the tool and the rules, it is tested apart
+		actual = stampLine.ReplaceAll(actual, []byte(stampPrefix+"<stamp>"))
 		golden.Assert(t, string(actual), filepath.Join(goldenDir, testName, entry.Name()))
 	}
 }
@@ -174,3 +293,241 @@ func actualFileFromGolden(t *testing.T, goldenName string) string {
 	}
 	return parts[1]
 }
+
+// TestHookContextIndices runs an instrumented method and checks what every
+// GetParam and GetReturnVal index resolves to in its hooks
+func TestHookContextIndices(t *testing.T) {
+	const targetSource = `package main
+
+type Recv struct{ name string }
+
+func (r *Recv) Target(name string, n int, items ...int) (string, error) {
+	return r.name + name, nil
+}
+
+func main() { (&Recv{name: "recv"}).Target("name", 7, 1, 2) }
+`
+	const hookSource = `package hooks
+
+import "fmt"
+
+func BeforeTarget(ictx HookContext, recv interface{}, name string, n int, items ...int) {
+	fmt.Println("before params", ictx.GetParamCount())
+	for i := 0; i < ictx.GetParamCount(); i++ {
+		fmt.Printf("before param %d %T %v\n", i, ictx.GetParam(i), ictx.GetParam(i))
+	}
+	fmt.Println("before returns", ictx.GetReturnValCount())
+}
+
+func AfterTarget(ictx HookContext, ret string, err error) {
+	fmt.Println("after returns", ictx.GetReturnValCount())
+	for i := 0; i < ictx.GetReturnValCount(); i++ {
+		fmt.Printf("after return %d %T %v\n", i, ictx.GetReturnVal(i), ictx.GetReturnVal(i))
+	}
+}
+`
+	lines := runHookedModule(t, targetSource, hookSource, `
+func: Target
+recv: "*Recv"
+before: BeforeTarget
+after: AfterTarget
+path: hookidx/hooks
+`)
+	// The HookContext takes no index, the receiver comes first and the
+	// variadic parameter is a single slice. Return values are only known to
+	// the After hook
+	require.Equal(t, []string{
+		"before params 4",
+		"before param 0 *main.Recv &{recv}",
+		"before param 1 string name",
+		"before param 2 int 7",
+		"before param 3 []int [1 2]",
+		"before returns 0",
+		"after returns 2",
+		"after return 0 string recvname",
+		"after return 1 <nil> <nil>",
+	}, lines)
+}
+
+func TestAfterHookAllReturnPaths(t *testing.T) {
+	// The compilation of the target has no import config, it cannot import
+	// packages
+	const targetSource = `package main
+
+type negativeError struct{}
+
+func (negativeError) Error() string { return "negative" }
+
+func Classify(n int) (kind string, err error) {
+	if n < 0 {
+		return "", negativeError{}
+	}
+	if n == 0 {
+		kind = "zero"
+		return
+	}
+	for i := 2; i < n; i++ {
+		if n%i == 0 {
+			return "composite", nil
+		}
+	}
+	return "prime", nil
+}
+
+func main() {
+	for _, n := range []int{-1, 0, 4, 7} {
+		Classify(n)
+	}
+}
+`
+	const hookSource = `package hooks
+
+import "fmt"
+
+func BeforeClassify(ictx HookContext, n int) {
+	fmt.Println("before", n)
+}
+
+func AfterClassify(ictx HookContext, kind string, err error) {
+	fmt.Println("after", kind, err)
+}
+`
+	lines := runHookedModule(t, targetSource, hookSource, `
+func: Classify
+before: BeforeClassify
+after: AfterClassify
+path: hookidx/hooks
+`)
+	// The After hook fires once per call whichever return statement ran,
+	// including a bare return of named results
+	require.Equal(t, []string{
+		"before -1",
+		"after  negative",
+		"before 0",
+		"after zero <nil>",
+		"before 4",
+		"after composite <nil>",
+		"before 7",
+		"after prime <nil>",
+	}, lines)
+}
+
+func TestHookNameCollisions(t *testing.T) {
+	// The names given to the unnamed and blank values must not collide with
+	// the declared ones, nor with the names of the trampoline parameters
+	const targetSource = `package main
+
+type T struct{}
+
+func (T) Many(_unnamedRetVal0 int, _unnamedParam0 string, _ bool, param1, recv0 int, hookContext string) (int, string, bool, int, error) {
+	return _unnamedRetVal0 + param1 + recv0, _unnamedParam0 + hookContext, true, 4, nil
+}
+
+func Unnamed(int, string) (_ int, err error) {
+	return 7, nil
+}
+
+func main() {
+	n, s, ok, four, err := T{}.Many(1, "a", true, 2, 3, "b")
+	println("Many", n, s, ok, four, err == nil)
+	n, err = Unnamed(5, "c")
+	println("Unnamed", n, err == nil)
+}
+`
+	const hookSource = `package hooks
+
+import "fmt"
+
+func BeforeMany(ictx HookContext, recv interface{}, a int, b string, c bool, d, e int, f string) {
+	fmt.Println("before", a, b, c, d, e, f)
+}
+
+func AfterMany(ictx HookContext, a int, b string, c bool, d int, err error) {
+	fmt.Println("after", a, b, c, d, err)
+}
+
+func BeforeUnnamed(ictx HookContext, n int, s string) {
+	fmt.Println("before", n, s)
+	ictx.SetSkipCall(true)
+}
+
+func AfterUnnamed(ictx HookContext, n int, err error) {
+	fmt.Println("after", n, err)
+	ictx.SetReturnVal(0, 42)
+}
+`
+	lines := runHookedModule(t, targetSource, hookSource, `
+func: Many
+recv: T
+before: BeforeMany
+after: AfterMany
+path: hookidx/hooks
+`)
+	require.Equal(t, []string{
+		"before 1 a true 2 3 b",
+		"after 6 ab true 4 <nil>",
+		"Many 6 ab true 4 true",
+		"Unnamed 7 true",
+	}, lines)
+
+	// The skipped call returns the blank result set by the after hook
+	lines = runHookedModule(t, targetSource, hookSource, `
+func: Unnamed
+before: BeforeUnnamed
+after: AfterUnnamed
+path: hookidx/hooks
+`)
+	require.Equal(t, []string{
+		"Many 6 ab true 4 true",
+		"before 5 c",
+		"after 0 <nil>",
+		"Unnamed 42 true",
+	}, lines)
+}
+
+// runHookedModule instruments the main package of a module made of
+// targetSource with the rule, whose hooks are in hookSource, runs it and
+// returns the lines it printed
+func runHookedModule(t *testing.T, targetSource, hookSource, ruleYAML string) []string {
+	t.Helper()
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	t.Setenv(util.EnvOtelWorkDir, tempDir)
+	ctx := util.ContextWithLogger(t.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// The hook path is both the directory of the hook sources, relative to
+	// the working directory, and the import path of the hook package
+	moduleDir := filepath.Join(tempDir, "hookidx")
+	require.NoError(t, os.MkdirAll(filepath.Join(moduleDir, "hooks"), 0o755))
+	// The hook package declares the HookContext interface itself, as the
+	// instrumented package does
+	packageClause := regexp.MustCompile(`(?m)^package \w+$`)
+	files := map[string]string{
+		"go.mod":       "module hookidx\n\ngo 1.23\n",
+		mainGoFileName: targetSource,
+		// The hook package is only linked in, the compilation of the target
+		// does not see this file
+		"link.go":              "package main\n\nimport _ \"hookidx/hooks\"\n",
+		"hooks/hooks.go":       hookSource,
+		"hooks/hookcontext.go": packageClause.ReplaceAllString(templateAPI, "package hooks"),
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(moduleDir, name), []byte(content), 0o600))
+	}
+	sourceFile := filepath.Join(moduleDir, mainGoFileName)
+	r, err := rule.NewInstFuncRule([]byte(ruleYAML), "hooked_module")
+	require.NoError(t, err)
+	writeMatchedJSON(&rule.InstRuleSet{
+		PackageName: mainPackage,
+		ModulePath:  mainPackage,
+		FuncRules:   map[string][]*rule.InstFuncRule{sourceFile: {r}},
+	})
+	require.NoError(t, Toolexec(ctx, compileArgs(moduleDir, sourceFile)))
+	require.NoError(t, os.Remove(filepath.Join(moduleDir, compiledOutput)))
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = moduleDir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
@@ -6,6 +6,7 @@ package instrument
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
@@ -37,10 +38,42 @@ func (ip *InstrumentPhase) match(allSet []*rule.InstRuleSet, args []string) *rul
 	importPath := util.FindFlagValue(args, "-p")
 	util.Assert(importPath != "", "sanity check")
 	for _, rset := range allSet {
-		if rset.ModulePath == importPath {
+		if rset.ModulePath == importPath && compilesRuleSet(rset, args) {
 			ip.Debug("Match rule set", "set", rset)
 			return rset
 		}
 	}
 	return nil
 }
+
+// compilesRuleSet reports whether the compile command compiles the files that
+// the rule set targets. Every main package is compiled with the import path
+// "main", so the import path alone cannot tell which of them the rule set was
+// matched against when a build contains several main packages.
+func compilesRuleSet(rset *rule.InstRuleSet, args []string) bool {
+	targets := make(map[string]bool)
+	for file := range rset.FuncRules {
+		targets[file] = true
+	}
+	for file := range rset.StructRules {
+		targets[file] = true
+	}
+	for file := range rset.RawRules {
+		targets[file] = true
+	}
+	// File rules apply to whatever package has the import path
+	if len(targets) == 0 {
+		return true
+	}
+	for _, arg := range args {
+		if !util.IsGoFile(arg) {
+			continue
+		}
+		// Files in the compile command maybe relative or absolute
+		abs, err := filepath.Abs(arg)
+		if err == nil && targets[abs] {
+			return true
+		}
+	}
+	return false
+}
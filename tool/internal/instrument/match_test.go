@@ -0,0 +1,36 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrument
+
+import (
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+)
+
+func TestMatchMainPackages(t *testing.T) {
+	// Two main packages of the same build share the import path "main"
+	dir := t.TempDir()
+	cmdA := filepath.Join(dir, "cmd", "a", "main.go")
+	cmdB := filepath.Join(dir, "cmd", "b", "main.go")
+	setA := rule.NewInstRuleSet("main")
+	setA.AddFuncRule(cmdA, &rule.InstFuncRule{Func: "Example", Before: "Before"})
+	setB := rule.NewInstRuleSet("main")
+	setB.AddRawRule(cmdB, &rule.InstRawRule{Func: "Example", Raw: "println()"})
+	setFile := rule.NewInstRuleSet("main")
+	setFile.AddFileRule(&rule.InstFileRule{File: "extra.go"})
+	ip := &InstrumentPhase{logger: slog.Default()}
+
+	args := []string{"compile", "-o", "_pkg_.a", "-p", "main", cmdB}
+	require.Same(t, setB, ip.match([]*rule.InstRuleSet{setA, setB}, args))
+	args = []string{"compile", "-o", "_pkg_.a", "-p", "main", cmdA}
+	require.Same(t, setA, ip.match([]*rule.InstRuleSet{setA, setB}, args))
+	require.Nil(t, ip.match([]*rule.InstRuleSet{setB}, args))
+	// File rules do not target any source file of the package
+	require.Same(t, setFile, ip.match([]*rule.InstRuleSet{setB, setFile}, args))
+}
@@ -136,11 +136,12 @@ func newHookContextImpl(tjump *TJump) dst.Expr {
 		returnExprs,
 	)
 
-	// Build the struct literal: &HookContextImpl{params:..., returnVals:...}
+	// Build the struct literal: &HookContextImpl{params:..., returnVals:..., ruleName:...}
 	return ast.StructLit(
 		structName,
 		ast.KeyValueExpr(trampolineParamsIdentifier, paramsSlice),
 		ast.KeyValueExpr(trampolineReturnValsIdentifier, returnValsSlice),
+		ast.KeyValueExpr(trampolineRuleNameIdentifier, ast.StringLit(tjump.rule.Name)),
 	)
 }
 
@@ -185,7 +185,7 @@ func TestNewHookContextImpl(t *testing.T) {
 				require.True(t, ok, "expression should be unary expression")
 				compositeLit, ok := unaryExpr.X.(*dst.CompositeLit)
 				require.True(t, ok, "expression should contain composite literal")
-				assert.Len(t, compositeLit.Elts, 2, "should have params and return values fields")
+				assert.Len(t, compositeLit.Elts, 3, "should have params, return values and rule name fields")
 
 				// Verify params field has correct number of parameter addresses
 				paramsKV, ok := compositeLit.Elts[0].(*dst.KeyValueExpr)
@@ -194,6 +194,12 @@ func TestNewHookContextImpl(t *testing.T) {
 				paramsLit, ok := paramsKV.Value.(*dst.CompositeLit)
 				require.True(t, ok, "params value should be CompositeLit")
 				assert.Len(t, paramsLit.Elts, 2, "should have 2 parameter addresses")
+
+				// Verify ruleName field carries the rule name
+				ruleNameKV, ok := compositeLit.Elts[2].(*dst.KeyValueExpr)
+				require.True(t, ok, "third element should be KeyValueExpr")
+				assert.Equal(t, "ruleName", ruleNameKV.Key.(*dst.Ident).Name)
+				assert.True(t, ast.IsStringLit(ruleNameKV.Value, "test_rule"), "ruleName should be the rule name")
 			},
 		},
 		{
@@ -259,7 +265,8 @@ func TestNewHookContextImpl(t *testing.T) {
 			tjump := &TJump{
 				target: targetFunc,
 				rule: &rule.InstFuncRule{
-					Func: targetFunc.Name.Name,
+					InstBaseRule: rule.InstBaseRule{Name: "test_rule"},
+					Func:         targetFunc.Name.Name,
 				},
 			}
 
@@ -0,0 +1,224 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrument
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
+)
+
+const (
+	// stampPrefix starts the first line of the globals file, followed by the
+	// stamp of the inputs the generated files are generated from
+	stampPrefix = "// otel:stamp "
+	// instrumentedCacheDir holds the generated files of every stamp under the
+	// build temp directory
+	instrumentedCacheDir = "instrumented"
+)
+
+// toolVersion identifies the build of the tool, the code it generates may
+// change with it
+func toolVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := bi.Main.Version
+	for _, setting := range bi.Settings {
+		if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+			version += " " + setting.Value
+		}
+	}
+	return version
+}
+
+// computeStamp hashes everything the generated files of rset depend on: the
+// tool version, the trampoline prefix, the rules, the content of the files
+// they target and the sources of the hook packages, which the trampolines are
+// generated from. Files are identified by their base name, or their path
+// within the hook package, so that the stamp does not depend on where the
+// package is built.
+func computeStamp(rset *rule.InstRuleSet) (string, error) {
+	h := sha256.New()
+	fmt.Fprintln(h, toolVersion(), util.GetTrampolinePrefix(), rset.PackageName, rset.ModulePath)
+	fileRules, err := json.Marshal(rset.FileRules)
+	if err != nil {
+		return "", ex.Wrap(err)
+	}
+	h.Write(fileRules)
+	file2rules := groupRules(rset)
+	files := make([]string, 0, len(file2rules))
+	for file := range file2rules {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		rules, err1 := json.Marshal(file2rules[file])
+		if err1 != nil {
+			return "", ex.Wrap(err1)
+		}
+		content, err1 := os.ReadFile(file)
+		if err1 != nil {
+			return "", ex.Wrap(err1)
+		}
+		fmt.Fprintln(h, filepath.Base(file))
+		h.Write(rules)
+		h.Write(content)
+	}
+	err = hashHookSources(h, rset)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashHookSources hashes the Go files of the packages the func and file rules
+// of rset take their hooks and files from
+func hashHookSources(h io.Writer, rset *rule.InstRuleSet) error {
+	paths := make(map[string]bool)
+	for _, rules := range rset.FuncRules {
+		for _, r := range rules {
+			paths[r.Path] = true
+		}
+	}
+	for _, r := range rset.FileRules {
+		paths[r.Path] = true
+	}
+	delete(paths, "")
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+	for _, path := range sorted {
+		files, err := listRuleFiles(path)
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+		fmt.Fprintln(h, path)
+		for _, file := range files {
+			if !util.IsGoFile(file) {
+				continue
+			}
+			content, err1 := os.ReadFile(file)
+			if err1 != nil {
+				return ex.Wrap(err1)
+			}
+			fmt.Fprintln(h, filepath.Base(file))
+			h.Write(content)
+		}
+	}
+	return nil
+}
+
+// readStamp returns the stamp of a globals file, or an empty string
+func readStamp(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, stampPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, stampPrefix))
+}
+
+// stampDir is where the files generated from the inputs of the stamp are
+// kept. The working directory is the $WORK of a single go build, the build
+// temp directory outlives it.
+func (ip *InstrumentPhase) stampDir() string {
+	return util.GetBuildTemp(filepath.Join(instrumentedCacheDir, ip.stamp))
+}
+
+// saveInstrumented keeps the files generated for rset in the stamp directory
+// for later builds to reuse. It is written in full before being renamed into
+// place, as packages are compiled concurrently.
+func (ip *InstrumentPhase) saveInstrumented(rset *rule.InstRuleSet) error {
+	globals := filepath.Join(ip.workDir, otelGlobalsFile)
+	dir := ip.stampDir()
+	if !util.PathExists(globals) || util.PathExists(dir) {
+		return nil
+	}
+	newFiles := []string{globals}
+	for _, r := range rset.FileRules {
+		newFiles = append(newFiles, ip.fileRuleOutput(r))
+	}
+	for file := range groupRules(rset) {
+		newFiles = append(newFiles, filepath.Join(ip.workDir, filepath.Base(file)))
+	}
+	err := os.MkdirAll(filepath.Dir(dir), 0o755)
+	if err != nil {
+		return ex.Wrap(err)
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ip.stamp+".tmp")
+	if err != nil {
+		return ex.Wrap(err)
+	}
+	defer os.RemoveAll(tmp)
+	for _, newFile := range newFiles {
+		err = util.CopyFile(newFile, filepath.Join(tmp, filepath.Base(newFile)))
+		if err != nil {
+			return err
+		}
+	}
+	// Another compile of the same inputs may have saved them meanwhile
+	if err = os.Rename(tmp, dir); err != nil && !util.PathExists(dir) {
+		return ex.Wrap(err)
+	}
+	return nil
+}
+
+// reuseInstrumented points the compile command to the files generated by a
+// previous build if they were generated from the same inputs, in which case
+// the package needs no instrumentation.
+func (ip *InstrumentPhase) reuseInstrumented(rset *rule.InstRuleSet) (bool, error) {
+	dir := ip.stampDir()
+	globals := filepath.Join(dir, otelGlobalsFile)
+	if readStamp(globals) != ip.stamp {
+		return false, nil
+	}
+	generated := make(map[string]string)
+	for file := range groupRules(rset) {
+		generated[file] = filepath.Join(dir, filepath.Base(file))
+	}
+	newFiles := []string{globals}
+	for _, r := range rset.FileRules {
+		newFiles = append(newFiles, filepath.Join(dir, filepath.Base(ip.fileRuleOutput(r))))
+	}
+	for _, newFile := range generated {
+		newFiles = append(newFiles, newFile)
+	}
+	for _, newFile := range newFiles {
+		if !util.PathExists(newFile) {
+			return false, nil
+		}
+	}
+	for file, newFile := range generated {
+		err := ip.replaceCompileArg(file, newFile)
+		if err != nil {
+			return false, err
+		}
+	}
+	for _, newFile := range newFiles[:1+len(rset.FileRules)] {
+		ip.addCompileArg(newFile)
+	}
+	ip.Info("Reuse instrumented files", "stamp", ip.stamp)
+	return true, nil
+}
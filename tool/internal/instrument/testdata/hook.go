@@ -34,3 +34,11 @@ func H7Before(ctx inst.HookContext) { ctx.SetSkipCall(true) }
 func H7After(ctx inst.HookContext) { _ = ctx }
 
 func H8After(ctx inst.HookContext, ret1 float32, ret2 error) {}
+
+func H9Before(ctx inst.HookContext, recv interface{}, p1 string) {}
+
+func H9After(ctx inst.HookContext, r1 string) {}
+
+func H10Before(ctx inst.HookContext, prefix string, items ...int) {}
+
+func H10After(ctx inst.HookContext) {}
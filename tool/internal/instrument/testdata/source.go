@@ -9,6 +9,17 @@ func (t *T) Func1(p1 string, p2 int) (float32, error) {
 	return 0.0, nil
 }
 
+type Base struct{}
+
+func (b Base) Name() string { return "base" }
+
+type V struct {
+	Base
+	name string
+}
+
+func (v V) Func3(p1 string) string { return v.name + p1 }
+
 func Func1(p1 string, p2 int) (float32, error) {
 	println("Hello, World!")
 	return 0.0, nil
@@ -16,6 +27,8 @@ func Func1(p1 string, p2 int) (float32, error) {
 
 func Func2(p1 string, _ int) {}
 
+func Variadic(prefix string, items ...int) {}
+
 func OptGood() {}
 func OptBad()  {}
 func OptBad2() {}
@@ -5,12 +5,14 @@ package instrument
 
 import (
 	"context"
+	"go/token"
 	"log/slog"
 	"path/filepath"
 	"strings"
 
 	"github.com/dave/dst"
 
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
 )
@@ -39,6 +41,8 @@ type InstrumentPhase struct {
 	hookCtxMethods []*dst.FuncDecl
 	// The trampoline jumps to be optimized
 	tjumps []*TJump
+	// The stamp of the inputs the generated files are generated from
+	stamp string
 }
 
 func (ip *InstrumentPhase) Info(msg string, args ...any)  { ip.logger.Info(msg, args...) }
@@ -74,6 +78,10 @@ func interceptCompile(ctx context.Context, args []string) ([]string, error) {
 	// Read compilation output directory
 	target := util.FindFlagValue(args, "-o")
 	util.Assert(target != "", "missing -o flag value")
+	// Trampoline names are generated from the prefix
+	if prefix := util.GetTrampolinePrefix(); !token.IsIdentifier(prefix) {
+		return nil, ex.Newf("invalid trampoline prefix %q", prefix)
+	}
 	ip := &InstrumentPhase{
 		logger:      util.LoggerFromContext(ctx),
 		workDir:     filepath.Dir(target),
@@ -90,11 +98,27 @@ func interceptCompile(ctx context.Context, args []string) ([]string, error) {
 	matched := ip.match(allSet, args)
 	if !matched.IsEmpty() {
 		ip.Info("Instrument package", "rules", matched, "args", args)
-		// Okay, this package should be instrumented.
-		err = ip.instrument(matched)
+		ip.stamp, err = computeStamp(matched)
 		if err != nil {
 			return nil, err
 		}
+		// Files generated from the same inputs by a previous build are
+		// reused, otherwise this package is instrumented
+		reused, err := ip.reuseInstrumented(matched)
+		if err != nil {
+			return nil, err
+		}
+		if !reused {
+			err = ip.instrument(matched)
+			if err != nil {
+				return nil, err
+			}
+			// Failing to save them only costs instrumenting again
+			err = ip.saveInstrumented(matched)
+			if err != nil {
+				ip.Warn("failed to save instrumented files", "stamp", ip.stamp, "error", err)
+			}
+		}
 
 		// Strip -complete flag as we may insert some hook points that are
 		// not ready yet, i.e. they don't have function body
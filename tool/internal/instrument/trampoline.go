@@ -32,6 +32,8 @@ import (
 const (
 	trampolineBeforeName            = "OtelBeforeTrampoline"
 	trampolineAfterName             = "OtelAfterTrampoline"
+	trampolineBeforeSuffix          = "BeforeTrampoline"
+	trampolineAfterSuffix           = "AfterTrampoline"
 	trampolineHookContextName       = "hookContext"
 	trampolineHookContextType       = "HookContext"
 	trampolineSkipName              = "skip"
@@ -40,18 +42,22 @@ const (
 	trampolineSetReturnValName      = "SetReturnVal"
 	trampolineGetReturnValName      = "GetReturnVal"
 	trampolineSetSkipCallName       = "SetSkipCall"
+	trampolineContextName           = "Context"
 	trampolineValIdentifier         = "val"
 	trampolineCtxIdentifier         = "c"
 	trampolineParamsIdentifier      = "params"
 	trampolineFuncNameIdentifier    = "funcName"
 	trampolinePackageNameIdentifier = "packageName"
+	trampolineRuleNameIdentifier    = "ruleName"
 	trampolineReturnValsIdentifier  = "returnVals"
 	trampolineHookContextImplType   = "HookContextImpl"
 	trampolineBeforeNamePlaceholder = `"OtelBeforeNamePlaceholder"`
 	trampolineAfterNamePlaceholder  = `"OtelAfterNamePlaceholder"`
+	trampolineRuleNamePlaceholder   = `"OtelRuleNamePlaceholder"`
 	trampolineBefore                = true
 	trampolineAfter                 = false
 	unsafePackageName               = "unsafe"
+	contextPackageName              = "context"
 )
 
 // @@ Modification on this trampoline template should be cautious, as it imposes
@@ -91,6 +97,26 @@ func (ip *InstrumentPhase) ensureUnsafeImport() {
 	ip.target.Decls = append([]dst.Decl{unsafeImport}, ip.target.Decls...)
 }
 
+// materializeVarDecls declares the variables of the trampoline template unless
+// a trampoline was created already. They are needed by the trampolines of the
+// functions instrumented by a previous run.
+func (ip *InstrumentPhase) materializeVarDecls() error {
+	if len(ip.varDecls) > 0 {
+		return nil
+	}
+	p := ast.NewAstParser()
+	astRoot, err := p.ParseSource(templateImpl)
+	if err != nil {
+		return err
+	}
+	for _, node := range astRoot.Decls {
+		if decl, ok := node.(*dst.GenDecl); ok && decl.Tok == token.VAR {
+			ip.varDecls = append(ip.varDecls, decl)
+		}
+	}
+	return nil
+}
+
 func (ip *InstrumentPhase) materializeTemplate() error {
 	// Read trampoline template and materialize before and after function
 	// declarations based on that
@@ -146,6 +172,9 @@ func (ip *InstrumentPhase) materializeTemplate() error {
 }
 
 func getNames(list *dst.FieldList) []string {
+	if list == nil {
+		return nil
+	}
 	var names []string
 	for _, field := range list.List {
 		for _, name := range field.Names {
@@ -388,6 +417,10 @@ func (ip *InstrumentPhase) renameTrampolineFunc(t *rule.InstFuncRule) {
 			if basicLit.Value == trampolineBeforeNamePlaceholder {
 				basicLit.Value = strconv.Quote(t.Before)
 			}
+			// Replace OtelRuleNamePlaceholder to the rule name reported on panic
+			if basicLit.Value == trampolineRuleNamePlaceholder {
+				basicLit.Value = strconv.Quote(t.Name)
+			}
 		}
 		return true
 	})
@@ -397,6 +430,9 @@ func (ip *InstrumentPhase) renameTrampolineFunc(t *rule.InstFuncRule) {
 			if basicLit.Value == trampolineAfterNamePlaceholder {
 				basicLit.Value = strconv.Quote(t.After)
 			}
+			if basicLit.Value == trampolineRuleNamePlaceholder {
+				basicLit.Value = strconv.Quote(t.Name)
+			}
 		}
 		return true
 	})
@@ -495,7 +531,7 @@ func assignSliceLiteral(assignStmt *dst.AssignStmt, vals []dst.Expr) bool {
 }
 
 // populateHookContext populates the hook context before hook invocation
-func (ip *InstrumentPhase) populateHookContext(before bool) bool {
+func (ip *InstrumentPhase) populateHookContext(t *rule.InstFuncRule, before bool) bool {
 	funcDecl := ip.beforeHookFunc
 	if !before {
 		funcDecl = ip.afterHookFunc
@@ -515,6 +551,11 @@ func (ip *InstrumentPhase) populateHookContext(before bool) bool {
 					// hookContext.PackageName = "..."
 					assigned := assignString(assignStmt, ip.target.Name.Name)
 					util.Assert(assigned, "sanity check")
+				case trampolineRuleNameIdentifier:
+					util.Assert(before, "sanity check")
+					// hookContext.RuleName = "..."
+					assigned := assignString(assignStmt, t.Name)
+					util.Assert(assigned, "sanity check")
 				default:
 					// hookContext.Params = []interface{}{...} or
 					// hookContext.(*HookContextImpl).Params[0] = &int
@@ -638,11 +679,61 @@ func desugarType(param *dst.Field) dst.Expr {
 	return param.Type
 }
 
+// importName returns the name under which the package of the given path is
+// imported in the file, or an empty string if it is not imported at all
+func importName(root *dst.File, path string) string {
+	for _, decl := range root.Decls {
+		genDecl, ok := decl.(*dst.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			importSpec, ok1 := spec.(*dst.ImportSpec)
+			if !ok1 || importSpec.Path.Value != strconv.Quote(path) {
+				continue
+			}
+			if importSpec.Name != nil {
+				return importSpec.Name.Name
+			}
+			return path
+		}
+	}
+	return ""
+}
+
+// findContextParam returns the HookContext parameter index of the first
+// context.Context parameter of the target function, or -1 if there is none.
+// The receiver, if any, counts as the first parameter.
+func findContextParam(root *dst.File, funcDecl *dst.FuncDecl) int {
+	pkg := importName(root, contextPackageName)
+	if pkg == "" || pkg == ast.IdentIgnore {
+		return -1
+	}
+	idx := 0
+	if ast.HasReceiver(funcDecl) {
+		idx++
+	}
+	for _, param := range funcDecl.Type.Params.List {
+		// Unnamed parameters are not addressable by the hook context
+		sel, ok := param.Type.(*dst.SelectorExpr)
+		if ok && sel.Sel.Name == trampolineContextName && len(param.Names) > 0 {
+			if x, ok1 := sel.X.(*dst.Ident); ok1 && x.Name == pkg {
+				return idx
+			}
+		}
+		idx += len(param.Names)
+	}
+	return -1
+}
+
 func (ip *InstrumentPhase) rewriteHookContext() {
 	util.Assert(len(ip.hookCtxMethods) > 4, "sanity check")
 	var methodSetParam, methodGetParam, methodGetRetVal, methodSetRetVal *dst.FuncDecl
+	var methodContext *dst.FuncDecl
 	for _, decl := range ip.hookCtxMethods {
 		switch decl.Name.Name {
+		case trampolineContextName:
+			methodContext = decl
 		case trampolineSetParamName:
 			methodSetParam = decl
 		case trampolineGetParamName:
@@ -685,6 +776,12 @@ func (ip *InstrumentPhase) rewriteHookContext() {
 			idx++
 		}
 	}
+	// Rewrite Context method to return the first context.Context parameter
+	if ctxIdx := findContextParam(ip.target, ip.targetFunc); ctxIdx != -1 {
+		getParam := ast.SelectorExpr(ast.Ident(trampolineCtxIdentifier), trampolineGetParamName)
+		call := &dst.CallExpr{Fun: getParam, Args: ast.Exprs(ast.IntLit(ctxIdx))}
+		methodContext.Body.List = ast.Stmts(ast.ReturnStmt(ast.Exprs(call)))
+	}
 	// Rewrite GetReturnVal and SetReturnVal methods
 	if ip.targetFunc.Type.Results != nil {
 		idx = 0
@@ -722,7 +819,7 @@ func (ip *InstrumentPhase) callHookFunc(t *rule.InstFuncRule, before bool) error
 		return err
 	}
 	// Fulfill the hook context before calling the real hook code.
-	if !ip.populateHookContext(before) {
+	if !ip.populateHookContext(t, before) {
 		return ex.New("failed to populate hook context")
 	}
 	return nil
@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrument
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+)
+
+func TestFindContextParam(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected int
+	}{
+		{
+			name: "context as second parameter",
+			source: `package main
+import "context"
+func F(n int, ctx context.Context) {}`,
+			expected: 1,
+		},
+		{
+			name: "aliased import with receiver",
+			source: `package main
+import stdctx "context"
+func (t *T) F(a, b string, ctx stdctx.Context, ctx2 stdctx.Context) {}`,
+			expected: 3,
+		},
+		{
+			name: "no context import",
+			source: `package main
+func F(n int, ctx context.Context) {}`,
+			expected: -1,
+		},
+		{
+			name: "no context parameter",
+			source: `package main
+import "context"
+func F(n int) context.Context { return nil }`,
+			expected: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ast.NewAstParser().ParseSource(tt.source)
+			require.NoError(t, err)
+			funcDecl, ok := root.Decls[len(root.Decls)-1].(*dst.FuncDecl)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, findContextParam(root, funcDecl))
+		})
+	}
+}
+
+// hookCall returns the call to the real hook guarded by the given if statement
+func hookCall(t *testing.T, stmt dst.Stmt) *dst.CallExpr {
+	iff, ok := stmt.(*dst.IfStmt)
+	require.True(t, ok, "expected if statement, got %T", stmt)
+	require.Len(t, iff.Body.List, 1)
+	exprStmt, ok := iff.Body.List[0].(*dst.ExprStmt)
+	require.True(t, ok)
+	call, ok := exprStmt.X.(*dst.CallExpr)
+	require.True(t, ok)
+	return call
+}
+
+func TestCallHooksWithoutParamsAndReturns(t *testing.T) {
+	root, err := ast.NewAstParser().ParseSource("package main\nfunc F() {}")
+	require.NoError(t, err)
+	ip := &InstrumentPhase{
+		target:     root,
+		targetFunc: ast.FindFuncDeclWithoutRecv(root, "F"),
+	}
+	require.NoError(t, ip.materializeTemplate())
+	ip.buildTrampolineTypes()
+	r := &rule.InstFuncRule{Func: "F", Before: "BeforeF", After: "AfterF"}
+	// Both hooks only declare the HookContext parameter
+	traits := []ParamTrait{{Index: 0}}
+
+	require.NoError(t, ip.callBeforeHook(r, traits))
+	body := ip.beforeHookFunc.Body.List
+	// The call is inserted right before the final return statement
+	call := hookCall(t, body[len(body)-2])
+	assert.Equal(t, "BeforeF", call.Fun.(*dst.Ident).Name)
+	require.Len(t, call.Args, 1)
+	assert.Equal(t, trampolineHookContextName, call.Args[0].(*dst.Ident).Name)
+
+	require.NoError(t, ip.callAfterHook(r, traits))
+	body = ip.afterHookFunc.Body.List
+	call = hookCall(t, body[len(body)-1])
+	assert.Equal(t, "AfterF", call.Fun.(*dst.Ident).Name)
+	require.Len(t, call.Args, 1)
+	assert.Equal(t, trampolineHookContextName, call.Args[0].(*dst.Ident).Name)
+}
+
+func TestHookContextKeyDataRoundTrip(t *testing.T) {
+	// A value stashed by the Before hook is read back by the After hook
+	const mainSource = `package main
+
+func main() {
+	hookContext, _ := OtelBeforeTrampoline()
+	hookContext.SetKeyData("start", 42)
+	hookContext.SetData("data")
+	OtelAfterTrampoline(hookContext)
+	after(hookContext)
+}
+
+func after(hookContext HookContext) {
+	if !hookContext.HasKeyData("start") || hookContext.HasKeyData("missing") {
+		panic("unexpected key data")
+	}
+	println(hookContext.GetKeyData("start").(int), hookContext.GetData().(string))
+}
+`
+	assert.Equal(t, "42 data\n", runTemplateProgram(t, mainSource))
+}
+
+func TestHookPanicReported(t *testing.T) {
+	// The After trampoline panics on the nil HookContext, the recovered panic
+	// is reported with the rule name and the phase
+	const mainSource = `package main
+
+func main() {
+	reported := ""
+	OtelReportPanicImpl = func(name, phase string) {
+		reported = name + " " + phase
+	}
+	OtelAfterTrampoline(nil)
+	if reported != "OtelRuleNamePlaceholder after" {
+		panic("unexpected panic report: " + reported)
+	}
+}
+`
+	output := runTemplateProgram(t, mainSource)
+	assert.Contains(t, output, "failed to exec After hook")
+}
+
+// runTemplateProgram builds the HookContext templates into a standalone
+// program along with mainSource and returns its output
+func runTemplateProgram(t *testing.T, mainSource string) string {
+	packageClause := regexp.MustCompile(`(?m)^package \w+$`)
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module hookcontext\n\ngo 1.23\n",
+		"api.go":  packageClause.ReplaceAllString(templateAPI, "package main"),
+		"impl.go": packageClause.ReplaceAllString(templateImpl, "package main"),
+		"main.go": mainSource,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return string(output)
+}
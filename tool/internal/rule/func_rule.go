@@ -21,6 +21,11 @@ import (
 //		recv: "*RecvType"
 //		before: "Foo"
 //		path: "github.com/foo/bar/hook_rule"
+//
+// Methods can also be targeted in method expression form, i.e. func "(*T).Bar"
+// or "T.Bar" without recv. Value and pointer receivers are distinct targets,
+// and a method promoted from an embedded field must be targeted on the type
+// that declares it.
 type InstFuncRule struct {
 	InstBaseRule `yaml:",inline"`
 
@@ -29,6 +34,12 @@ type InstFuncRule struct {
 	Before string `json:"before" yaml:"before"` // The function we inject at the target function entry
 	After  string `json:"after"  yaml:"after"`  // The function we inject at the target function exit
 	Path   string `json:"path"   yaml:"path"`   // The module path where hook code is located
+	// The kind of spans created by the hook, one of internal, client, server,
+	// producer or consumer. Defaults to internal
+	SpanKind string `json:"span_kind,omitempty" yaml:"span_kind"`
+	// The minimum number of statements in the body of the target function,
+	// smaller functions are not instrumented. Disabled when zero
+	MinStatements int `json:"min_statements,omitempty" yaml:"min_statements"`
 }
 
 // NewInstFuncRule loads and validates an InstFuncRule from YAML data.
@@ -40,6 +51,9 @@ func NewInstFuncRule(data []byte, name string) (*InstFuncRule, error) {
 	if r.Name == "" {
 		r.Name = name
 	}
+	if err := r.normalize(); err != nil {
+		return nil, ex.Wrapf(err, "invalid func rule %q", name)
+	}
 	if err := r.validate(); err != nil {
 		return nil, ex.Wrapf(err, "invalid func rule %q", name)
 	}
@@ -53,5 +67,40 @@ func (r *InstFuncRule) validate() error {
 	if r.Before == "" && r.After == "" {
 		return ex.Newf("before or after must be set")
 	}
+	switch r.SpanKind {
+	case "", "internal", "client", "server", "producer", "consumer":
+	default:
+		return ex.Newf("unknown span kind %q", r.SpanKind)
+	}
+	if r.MinStatements < 0 {
+		return ex.Newf("min_statements cannot be negative")
+	}
+	return nil
+}
+
+// normalize splits method expression targets such as "(*T).Bar" into Func and
+// Recv, and strips the optional parentheses around Recv.
+func (r *InstFuncRule) normalize() error {
+	r.Recv = trimRecv(r.Recv)
+	idx := strings.LastIndex(r.Func, ".")
+	if idx < 0 {
+		return nil
+	}
+	recv, fn := trimRecv(r.Func[:idx]), strings.TrimSpace(r.Func[idx+1:])
+	if r.Recv != "" && r.Recv != recv {
+		return ex.Newf("receiver %q of func %q conflicts with recv %q", recv, r.Func, r.Recv)
+	}
+	r.Func, r.Recv = fn, recv
 	return nil
 }
+
+func trimRecv(recv string) string {
+	recv = strings.TrimSpace(recv)
+	if strings.HasPrefix(recv, "(") && strings.HasSuffix(recv, ")") {
+		recv = strings.TrimSpace(recv[1 : len(recv)-1])
+	}
+	if strings.HasPrefix(recv, "*") {
+		recv = "*" + strings.TrimSpace(recv[1:])
+	}
+	return recv
+}
@@ -0,0 +1,21 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInstFuncRuleNegativeMinStatements(t *testing.T) {
+	_, err := NewInstFuncRule([]byte(`
+target: example.com/app/foo
+func: Bar
+before: Before
+path: example.com/app/hooks
+min_statements: -1
+`), "negative")
+	require.ErrorContains(t, err, "min_statements cannot be negative")
+}
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rule
+
+import (
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/ex"
+)
+
+// Registry holds rules registered programmatically, e.g. by build pipelines
+// embedding the tool, as an alternative to rule files. Rules are validated as
+// if they were loaded from YAML and their names must be unique.
+type Registry struct {
+	mu    sync.Mutex
+	rules []InstRule
+	names map[string]struct{}
+}
+
+// DefaultRegistry holds the rules matched alongside the embedded ones.
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]struct{})}
+}
+
+// Register validates and adds the rule, it fails if a rule with the same name
+// was registered before.
+func (r *Registry) Register(rule InstRule) error {
+	name := rule.GetName()
+	if name == "" {
+		return ex.Newf("rule name cannot be empty")
+	}
+	if err := validateRule(rule); err != nil {
+		return ex.Wrapf(err, "invalid rule %q", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.names[name]; ok {
+		return ex.Newf("rule %q is already registered", name)
+	}
+	r.names[name] = struct{}{}
+	r.rules = append(r.rules, rule)
+	return nil
+}
+
+// Rules returns the registered rules in registration order.
+func (r *Registry) Rules() []InstRule {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]InstRule(nil), r.rules...)
+}
+
+func validateRule(rule InstRule) error {
+	switch rt := rule.(type) {
+	case *InstFuncRule:
+		if err := rt.normalize(); err != nil {
+			return err
+		}
+		return rt.validate()
+	case *InstRawRule:
+		return rt.validate()
+	case *InstStructRule:
+		return rt.validate()
+	case *InstFileRule:
+		return rt.validate()
+	default:
+		return ex.Newf("unsupported rule type %T", rule)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	first := &InstFuncRule{
+		InstBaseRule: InstBaseRule{Name: "first", Target: "main"},
+		Func:         "(*T).Foo",
+		Before:       "BeforeFoo",
+		Path:         "example.com/hooks",
+	}
+	second := &InstRawRule{
+		InstBaseRule: InstBaseRule{Name: "second", Target: "main"},
+		Func:         "Bar",
+		Raw:          `println("bar")`,
+	}
+	require.NoError(t, registry.Register(first))
+	require.NoError(t, registry.Register(second))
+
+	rules := registry.Rules()
+	require.Equal(t, []InstRule{first, second}, rules)
+	// Rules are normalized as when loaded from YAML
+	require.Equal(t, "Foo", first.Func)
+	require.Equal(t, "*T", first.Recv)
+
+	err := registry.Register(&InstFuncRule{
+		InstBaseRule: InstBaseRule{Name: "first", Target: "main"},
+		Func:         "Baz",
+		After:        "AfterBaz",
+	})
+	require.ErrorContains(t, err, `rule "first" is already registered`)
+	err = registry.Register(&InstFuncRule{
+		InstBaseRule: InstBaseRule{Name: "invalid", Target: "main"},
+		Func:         "Baz",
+	})
+	require.ErrorContains(t, err, "before or after must be set")
+	require.Len(t, registry.Rules(), 2)
+}
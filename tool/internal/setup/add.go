@@ -10,6 +10,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/ast"
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
+	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/util"
 )
 
 const (
@@ -21,6 +22,8 @@ var requiredImports = map[string]string{
 	"runtime/debug": "_otel_debug", // The getstack function depends on runtime/debug
 	"log":           "_otel_log",   // The printstack function depends on log
 	"unsafe":        "_",           // The golinkname tag depends on unsafe
+	// The reportpanic function depends on pkg/inst
+	util.OtelRoot + "/pkg/inst": "_otel_inst",
 }
 
 func genImportDecl(matched []*rule.InstFuncRule) []dst.Decl {
@@ -80,7 +83,16 @@ func genVarDecl(matched []*rule.InstFuncRule) []dst.Decl {
 			NodeDecs: ast.LineComments(
 				fmt.Sprintf("//go:linkname _printstack%d %s.OtelPrintStackImpl", i, m.Path)),
 		}
-		decls = append(decls, getStackVar, printStackVar)
+		// Third variable declaration
+		// //go:linkname _reportpanic%d %s.OtelReportPanicImpl
+		// var _reportpanic%d = _otel_inst.RecordHookPanic
+		value = ast.SelectorExpr(ast.Ident("_otel_inst"), "RecordHookPanic")
+		reportPanicVar := ast.VarDecl(fmt.Sprintf("_reportpanic%d", i), value)
+		reportPanicVar.Decs = dst.GenDeclDecorations{
+			NodeDecs: ast.LineComments(
+				fmt.Sprintf("//go:linkname _reportpanic%d %s.OtelReportPanicImpl", i, m.Path)),
+		}
+		decls = append(decls, getStackVar, printStackVar, reportPanicVar)
 	}
 	return decls
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/dave/dst"
 	"golang.org/x/mod/semver"
 	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
@@ -73,7 +74,8 @@ func parseEmbeddedRule(path string) ([]rule.InstRule, error) {
 	return rules, nil
 }
 
-// materializeRules materializes all available rules from the embedded data
+// materializeRules materializes all available rules from the embedded data and
+// the default registry
 func materializeRules() ([]rule.InstRule, error) {
 	availables, err := data.ListEmbedFiles()
 	if err != nil {
@@ -88,6 +90,8 @@ func materializeRules() ([]rule.InstRule, error) {
 		}
 		parsedRules = append(parsedRules, rs...)
 	}
+	// Rules registered programmatically need no rule file
+	parsedRules = append(parsedRules, rule.DefaultRegistry.Rules()...)
 	return parsedRules, nil
 }
 
@@ -115,6 +119,16 @@ func matchVersion(dependency *Dependency, rule rule.InstRule) bool {
 	return semver.Compare(dependency.Version, ruleVersion) >= 0
 }
 
+// hasMinStatements reports whether the body of funcDecl has at least minStmts
+// statements. Functions without body, i.e. implemented in assembly, never
+// reach a minimum.
+func hasMinStatements(funcDecl *dst.FuncDecl, minStmts int) bool {
+	if minStmts <= 0 {
+		return true
+	}
+	return funcDecl.Body != nil && ast.CountStmts(funcDecl.Body) >= minStmts
+}
+
 // runMatch performs precise matching of rules against the dependency's source code.
 // It parses source files and matches rules by examining AST nodes
 func (sp *SetupPhase) runMatch(dep *Dependency, rulesByTarget map[string][]rule.InstRule) (*rule.InstRuleSet, error) {
@@ -171,10 +185,15 @@ func (sp *SetupPhase) runMatch(dep *Dependency, rulesByTarget map[string][]rule.
 			switch rt := r.(type) {
 			case *rule.InstFuncRule:
 				funcDecl := ast.FindFuncDecl(tree, rt.Func, rt.Recv)
-				if funcDecl != nil {
-					set.AddFuncRule(source, rt)
-					sp.Info("Match func rule", "rule", rt, "dep", dep)
+				if funcDecl == nil {
+					continue
+				}
+				if !hasMinStatements(funcDecl, rt.MinStatements) {
+					sp.Info("Skip func rule below min statements", "rule", rt, "dep", dep)
+					continue
 				}
+				set.AddFuncRule(source, rt)
+				sp.Info("Match func rule", "rule", rt, "dep", dep)
 			case *rule.InstStructRule:
 				structDecl := ast.FindStructDecl(tree, rt.Struct)
 				if structDecl != nil {
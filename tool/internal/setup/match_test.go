@@ -4,6 +4,10 @@
 package setup
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/open-telemetry/opentelemetry-go-compile-instrumentation/tool/internal/rule"
@@ -296,3 +300,104 @@ func validateCreatedRule(t *testing.T, createdRule rule.InstRule, ruleName strin
 		}
 	}
 }
+
+func TestRunMatchInternalPackage(t *testing.T) {
+	const importPath = "example.com/app/internal/foo"
+	source := filepath.Join(t.TempDir(), "foo.go")
+	err := os.WriteFile(source, []byte("package foo\n\nfunc Bar() {}\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := rule.NewInstFuncRule([]byte(`
+target: example.com/app/internal/foo
+func: Bar
+before: BeforeBar
+path: example.com/app/hooks
+`), "internal-rule")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sp := &SetupPhase{logger: slog.Default()}
+	dep := &Dependency{ImportPath: importPath, Sources: []string{source}}
+	set, err := sp.runMatch(dep, map[string][]rule.InstRule{importPath: {r}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.ModulePath != importPath || set.PackageName != "foo" {
+		t.Fatalf("unexpected rule set %s/%s", set.ModulePath, set.PackageName)
+	}
+	if rules := set.FuncRules[source]; len(rules) != 1 || rules[0] != r {
+		t.Fatalf("expected the rule to match the internal package, got %v", set.FuncRules)
+	}
+}
+
+func TestRunMatchMinStatements(t *testing.T) {
+	const importPath = "example.com/app/foo"
+	source := filepath.Join(t.TempDir(), "foo.go")
+	err := os.WriteFile(source, []byte(`package foo
+
+type T struct{ n int }
+
+func (t *T) Get() int { return t.n }
+
+func Empty() {}
+
+func Medium(n int) int {
+	n++
+	return n
+}
+
+func Large(ns []int) int {
+	sum := 0
+	for _, n := range ns {
+		if n > 0 {
+			sum += n
+		}
+	}
+	return sum
+}
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := make([]rule.InstRule, 0)
+	for _, target := range []string{"(*T).Get", "Empty", "Medium", "Large"} {
+		r, err1 := rule.NewInstFuncRule([]byte(`
+target: example.com/app/foo
+func: "`+target+`"
+before: Before
+path: example.com/app/hooks
+min_statements: 3
+`), target)
+		if err1 != nil {
+			t.Fatal(err1)
+		}
+		rules = append(rules, r)
+	}
+	disabled, err := rule.NewInstFuncRule([]byte(`
+target: example.com/app/foo
+func: Empty
+before: Before
+path: example.com/app/hooks
+`), "disabled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules = append(rules, disabled)
+
+	sp := &SetupPhase{logger: slog.Default()}
+	dep := &Dependency{ImportPath: importPath, Sources: []string{source}}
+	set, err := sp.runMatch(dep, map[string][]rule.InstRule{importPath: rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	matched := make([]string, 0)
+	for _, r := range set.FuncRules[source] {
+		matched = append(matched, r.Name)
+	}
+	// Large has 5 statements, Medium 2 and Get 1
+	if want := []string{"Large", "disabled"}; !slices.Equal(matched, want) {
+		t.Fatalf("matched rules = %v, want %v", matched, want)
+	}
+}
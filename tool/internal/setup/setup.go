@@ -122,6 +122,12 @@ func BuildWithToolexec(ctx context.Context, args []string) error {
 
 func GoBuild(ctx context.Context, args []string) error {
 	logger := util.LoggerFromContext(ctx)
+	// The instrumentation is compiled out entirely, neither the hooks nor their
+	// dependencies are linked
+	if util.HasBuildTag(args, util.BuildTagNoop) {
+		logger.InfoContext(ctx, "Skip instrumentation", "tag", util.BuildTagNoop)
+		return util.RunCmd(ctx, append([]string{"go"}, args...)...)
+	}
 	backupFiles := []string{"go.mod", "go.sum", "go.work", "go.work.sum"}
 	err := util.BackupFile(backupFiles)
 	if err != nil {
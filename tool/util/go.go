@@ -44,6 +44,32 @@ func FindFlagValue(cmd []string, flag string) string {
 	return ""
 }
 
+// HasBuildTag checks if the go command line enables the build tag, i.e. in the
+// "-tags a,b" or "-tags=a,b" form. The last -tags flag wins, as for the go
+// command.
+func HasBuildTag(args []string, tag string) bool {
+	tags := ""
+	for i, arg := range args {
+		// Flags may be written with two dashes
+		if strings.HasPrefix(arg, "--") {
+			arg = arg[1:]
+		}
+		switch {
+		case arg == "-tags" && i+1 < len(args):
+			tags = args[i+1]
+		case strings.HasPrefix(arg, "-tags="):
+			tags = strings.TrimPrefix(arg, "-tags=")
+		}
+	}
+	// Tags used to be separated by spaces, which is still accepted
+	for _, t := range strings.FieldsFunc(tags, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // SplitCompileCmds splits the command line by space, but keep the quoted part
 // as a whole. For example, "a b" c will be split into ["a b", "c"].
 func SplitCompileCmds(input string) []string {
@@ -10,9 +10,15 @@ import (
 )
 
 const (
-	EnvOtelWorkDir = "OTEL_WORK_DIR"
-	BuildTempDir   = ".otel-build"
-	OtelRoot       = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation"
+	EnvOtelWorkDir          = "OTEL_WORK_DIR"
+	EnvOtelTrampolinePrefix = "OTEL_TRAMPOLINE_PREFIX"
+	BuildTempDir            = ".otel-build"
+	OtelRoot                = "github.com/open-telemetry/opentelemetry-go-compile-instrumentation"
+	// DefaultTrampolinePrefix namespaces the generated trampoline functions,
+	// e.g. OtelBeforeTrampoline_Foo
+	DefaultTrampolinePrefix = "Otel"
+	// BuildTagNoop compiles the instrumentation out, the project is built as is
+	BuildTagNoop = "otelnoop"
 )
 
 func GetMatchedRuleFile() string {
@@ -29,6 +35,16 @@ func GetOtelWorkDir() string {
 	return wd
 }
 
+// GetTrampolinePrefix returns the prefix of the generated trampoline functions,
+// it can be changed to avoid collisions with functions of the user code.
+func GetTrampolinePrefix() string {
+	prefix := os.Getenv(EnvOtelTrampolinePrefix)
+	if prefix == "" {
+		return DefaultTrampolinePrefix
+	}
+	return prefix
+}
+
 // GetBuildTemp returns the path to the build temp directory $BUILD_TEMP/name
 func GetBuildTempDir() string {
 	return filepath.Join(GetOtelWorkDir(), BuildTempDir)
@@ -66,3 +66,23 @@ func TestSplitCompileCmds(t *testing.T) {
 		})
 	}
 }
+
+func TestHasBuildTag(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"build", "-tags", "otelnoop", "."}, true},
+		{[]string{"build", "-tags=netgo,otelnoop", "."}, true},
+		{[]string{"build", "--tags", "netgo otelnoop"}, true},
+		{[]string{"build", "-tags", "otelnoop", "-tags", "netgo"}, false},
+		{[]string{"build", "-tags", "otelnoopx"}, false},
+		{[]string{"build", "otelnoop"}, false},
+		{[]string{"build", "-tags"}, false},
+	}
+	for _, tt := range tests {
+		if got := HasBuildTag(tt.args, BuildTagNoop); got != tt.expected {
+			t.Errorf("HasBuildTag(%v) = %v, want %v", tt.args, got, tt.expected)
+		}
+	}
+}